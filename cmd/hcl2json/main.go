@@ -0,0 +1,72 @@
+// Command hcl2json converts HCL to JSON.
+//
+// Usage:
+//
+//	hcl2json [flags] [file]
+//
+// HCL is read from file, or stdin if file is omitted or "-", and JSON is written
+// to stdout. See hcl.MarshalASTToJSON for the JSON representation.
+//
+// The exit code is 0 on success, 1 if the input could not be converted, and 2
+// on invalid usage. Errors are reported as "file:line:column: message".
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+func main() {
+	comments := flag.Bool("comments", false, "include comments under the reserved \"//\" key")
+	indent := flag.String("indent", "  ", "indentation for pretty-printing, or empty for compact output")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hcl2json [flags] [file]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *comments, *indent); err != nil {
+		fmt.Fprintf(os.Stderr, "hcl2json: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string, comments bool, indent string) error {
+	r := io.Reader(os.Stdin)
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	} else {
+		path = ""
+	}
+	ast, err := hcl.Parse(r, hcl.WithFilename(path))
+	if err != nil {
+		return err
+	}
+	data, err := hcl.MarshalASTToJSON(ast, hcl.JSONComments(comments))
+	if err != nil {
+		return err
+	}
+	if indent != "" {
+		w := &bytes.Buffer{}
+		if err := json.Indent(w, data, "", indent); err != nil {
+			return err
+		}
+		data = w.Bytes()
+	}
+	_, err = fmt.Fprintf(os.Stdout, "%s\n", data)
+	return err
+}
@@ -0,0 +1,69 @@
+// Command hclcompat reports differences between two versions of an HCL schema.
+//
+// Usage:
+//
+//	hclcompat [flags] old new
+//
+// Each change between the schemas, such as those produced by hcl.Schema, is
+// written to stdout. See hcl.CompareSchemas for which changes are breaking.
+//
+// The exit code is 0 if there are no breaking changes, 1 if there are breaking
+// changes or a schema could not be read, and 2 on invalid usage. Errors are
+// reported as "file:line:column: message".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+func main() {
+	breakingOnly := flag.Bool("breaking", false, "only report breaking changes")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hclcompat [flags] old new\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	breaking, err := run(flag.Arg(0), flag.Arg(1), *breakingOnly)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "hclcompat: %s\n", err)
+		os.Exit(1)
+	}
+	if breaking {
+		os.Exit(1)
+	}
+}
+
+func run(beforePath, afterPath string, breakingOnly bool) (breaking bool, err error) {
+	before, err := parse(beforePath)
+	if err != nil {
+		return false, err
+	}
+	after, err := parse(afterPath)
+	if err != nil {
+		return false, err
+	}
+	for _, change := range hcl.CompareSchemas(before, after) {
+		breaking = breaking || change.Breaking
+		if breakingOnly && !change.Breaking {
+			continue
+		}
+		fmt.Println(change)
+	}
+	return breaking, nil
+}
+
+func parse(path string) (*hcl.AST, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return hcl.ParseBytes(data, hcl.WithFilename(path), hcl.WithSchema(true))
+}
@@ -0,0 +1,129 @@
+// Command hclconvert converts YAML and TOML documents to and from HCL.
+//
+// Usage:
+//
+//	hclconvert [flags] [file]
+//
+// The document is read from file, or stdin if file is omitted or "-", and the
+// converted document is written to stdout. The input format is inferred from
+// the file extension unless -from is given, and the output format defaults to
+// HCL for YAML and TOML input. See package convert for how documents are mapped.
+//
+// The exit code is 0 on success, 1 if the input could not be converted, and 2
+// on invalid usage.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/hcl/v2"
+	"github.com/alecthomas/hcl/v2/convert"
+)
+
+func main() {
+	from := flag.String("from", "", "input format: hcl, yaml or toml")
+	to := flag.String("to", "", "output format: hcl, yaml or toml")
+	schema := flag.String("schema", "", "HCL schema file used to map YAML and TOML to blocks, labels and maps")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hclconvert [flags] [file]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+	if path == "-" {
+		path = ""
+	}
+	if *from == "" {
+		*from = formatFromExt(path)
+	}
+	if *to == "" && *from != "hcl" {
+		*to = "hcl"
+	}
+	if !validFormat(*from) || !validFormat(*to) || *from == *to {
+		fmt.Fprintf(os.Stderr, "hclconvert: can't convert from %q to %q, use -from and -to\n", *from, *to)
+		os.Exit(2)
+	}
+	if err := run(path, *from, *to, *schema); err != nil {
+		fmt.Fprintf(os.Stderr, "hclconvert: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func formatFromExt(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		return "yaml"
+	case ".toml":
+		return "toml"
+	case ".hcl":
+		return "hcl"
+	}
+	return ""
+}
+
+func validFormat(format string) bool {
+	return format == "hcl" || format == "yaml" || format == "toml"
+}
+
+func run(path, from, to, schemaPath string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if path != "" {
+		data, err = os.ReadFile(path)
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+	options := []convert.Option{convert.WithFilename(path)}
+	if schemaPath != "" {
+		schemaData, err := os.ReadFile(schemaPath)
+		if err != nil {
+			return err
+		}
+		schema, err := hcl.ParseBytes(schemaData, hcl.WithFilename(schemaPath), hcl.WithSchema(true))
+		if err != nil {
+			return err
+		}
+		options = append(options, convert.WithSchema(schema))
+	}
+
+	var ast *hcl.AST
+	switch from {
+	case "yaml":
+		ast, err = convert.FromYAML(data, options...)
+	case "toml":
+		ast, err = convert.FromTOML(data, options...)
+	default:
+		ast, err = hcl.ParseBytes(data, hcl.WithFilename(path))
+	}
+	if err != nil {
+		return err
+	}
+
+	switch to {
+	case "yaml":
+		data, err = convert.ToYAML(ast)
+	case "toml":
+		data, err = convert.ToTOML(ast)
+	default:
+		data, err = hcl.MarshalAST(ast)
+	}
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
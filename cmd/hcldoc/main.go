@@ -0,0 +1,74 @@
+// Command hcldoc renders reference documentation from an HCL schema.
+//
+// Usage:
+//
+//	hcldoc [flags] [schema]
+//
+// The schema, such as one produced by hcl.Schema, is read from schema, or
+// stdin if schema is omitted or "-", and documentation is written to stdout.
+// See hcl.DocumentAST for the layout of the documentation.
+//
+// The exit code is 0 on success, 1 if the schema could not be documented, and
+// 2 on invalid usage. Errors are reported as "file:line:column: message".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+func main() {
+	format := flag.String("format", "markdown", "output format, one of markdown or html")
+	title := flag.String("title", "Configuration", "title of the top-level section")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hcldoc [flags] [schema]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	var docFormat hcl.DocFormat
+	switch *format {
+	case "markdown":
+		docFormat = hcl.DocMarkdown
+	case "html":
+		docFormat = hcl.DocHTML
+	default:
+		fmt.Fprintf(os.Stderr, "hcldoc: unknown format %q\n", *format)
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), docFormat, *title); err != nil {
+		fmt.Fprintf(os.Stderr, "hcldoc: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string, format hcl.DocFormat, title string) error {
+	r := io.Reader(os.Stdin)
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	} else {
+		path = ""
+	}
+	schema, err := hcl.Parse(r, hcl.WithFilename(path), hcl.WithSchema(true))
+	if err != nil {
+		return err
+	}
+	data, err := hcl.DocumentAST(schema, hcl.WithDocFormat(format), hcl.WithDocTitle(title))
+	if err != nil {
+		return err
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
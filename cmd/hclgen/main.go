@@ -0,0 +1,68 @@
+// Command hclgen generates Go structs from an HCL schema or sample document.
+//
+// Usage:
+//
+//	hclgen [flags] [file]
+//
+// The schema, such as one produced by hcl.Schema, or a sample document is read
+// from file, or stdin if file is omitted or "-", and Go source is written to
+// stdout or the file given by -o. See hcl.GenerateGo for details.
+//
+// The exit code is 0 on success, 1 if the input could not be converted, and 2
+// on invalid usage. Errors are reported as "file:line:column: message".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+func main() {
+	pkg := flag.String("package", "config", "package name of the generated source")
+	root := flag.String("type", "Config", "name of the top-level struct")
+	output := flag.String("o", "", "file to write the generated source to, instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hclgen [flags] [file]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0), *output, *pkg, *root); err != nil {
+		fmt.Fprintf(os.Stderr, "hclgen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path, output, pkg, root string) error {
+	r := io.Reader(os.Stdin)
+	if path != "" && path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	} else {
+		path = ""
+	}
+	schema, err := hcl.Parse(r, hcl.WithFilename(path), hcl.WithSchema(true))
+	if err != nil {
+		return err
+	}
+	data, err := hcl.GenerateGo(schema, hcl.GoPackage(pkg), hcl.GoRootType(root))
+	if err != nil {
+		return err
+	}
+	if output != "" {
+		return os.WriteFile(output, data, 0644)
+	}
+	_, err = os.Stdout.Write(data)
+	return err
+}
@@ -0,0 +1,113 @@
+// Command hclq selects nodes from HCL files using hcl.Query path expressions.
+//
+// Usage:
+//
+//	hclq [flags] <path> [file...]
+//
+// HCL is read from each file, or stdin if no files are given or a file is "-".
+// Matching blocks, attributes and map entries are written to stdout as HCL, or
+// as one JSON document per match with -json.
+//
+// As with grep, the exit code is 0 if any nodes matched, 1 if none matched, and
+// 2 on error.
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+func main() {
+	jsonOutput := flag.Bool("json", false, "write matches as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: hclq [flags] <path> [file...]\n\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	expr := flag.Arg(0)
+	paths := flag.Args()[1:]
+	if len(paths) == 0 {
+		paths = []string{"-"}
+	}
+	matched := false
+	for _, path := range paths {
+		ok, err := run(expr, path, *jsonOutput)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "hclq: %s\n", err)
+			os.Exit(2)
+		}
+		matched = matched || ok
+	}
+	if !matched {
+		os.Exit(1)
+	}
+}
+
+func run(expr, path string, jsonOutput bool) (bool, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return false, err
+		}
+		defer f.Close()
+		r = f
+	} else {
+		path = ""
+	}
+	ast, err := hcl.Parse(r, hcl.WithFilename(path))
+	if err != nil {
+		return false, err
+	}
+	nodes, err := hcl.Query(ast, expr)
+	if err != nil {
+		return false, err
+	}
+	for _, node := range nodes {
+		if jsonOutput {
+			err = writeJSON(node)
+		} else {
+			err = hcl.MarshalASTToWriter(node, os.Stdout)
+		}
+		if err != nil {
+			return false, err
+		}
+	}
+	return len(nodes) > 0, nil
+}
+
+func writeJSON(node hcl.Node) error {
+	var entry hcl.Entry
+	switch node := node.(type) {
+	case hcl.Entry:
+		entry = node
+	case *hcl.MapEntry:
+		key := node.Key.String()
+		if str, ok := node.Key.(*hcl.String); ok {
+			key = str.Str
+		}
+		entry = &hcl.Attribute{Key: key, Value: node.Value}
+	default:
+		return fmt.Errorf("can't convert %T to JSON", node)
+	}
+	data, err := hcl.MarshalASTToJSON(&hcl.AST{Entries: []hcl.Entry{entry}})
+	if err != nil {
+		return err
+	}
+	w := &bytes.Buffer{}
+	if err := json.Indent(w, data, "", "  "); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(os.Stdout, "%s\n", w)
+	return err
+}
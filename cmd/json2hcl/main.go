@@ -0,0 +1,57 @@
+// Command json2hcl converts JSON to HCL.
+//
+// Usage:
+//
+//	json2hcl [file]
+//
+// JSON is read from file, or stdin if file is omitted or "-", and HCL is written
+// to stdout. See hcl.ParseJSON for how JSON is mapped to HCL.
+//
+// The exit code is 0 on success, 1 if the input could not be converted, and 2
+// on invalid usage. Errors are reported as "file:line:column: message".
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+func main() {
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: json2hcl [file]\n")
+	}
+	flag.Parse()
+	if flag.NArg() > 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := run(flag.Arg(0)); err != nil {
+		fmt.Fprintf(os.Stderr, "json2hcl: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+func run(path string) error {
+	var (
+		data []byte
+		err  error
+	)
+	if path != "" && path != "-" {
+		data, err = os.ReadFile(path)
+	} else {
+		path = ""
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+	ast, err := hcl.ParseJSON(data, hcl.WithFilename(path))
+	if err != nil {
+		return err
+	}
+	return hcl.MarshalASTToWriter(ast, os.Stdout)
+}
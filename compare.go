@@ -0,0 +1,327 @@
+package hcl
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ChangeKind classifies a difference between two schemas.
+type ChangeKind int
+
+// Kinds of change reported by CompareSchemas.
+const (
+	AttributeAdded ChangeKind = iota
+	AttributeRemoved
+	BlockAdded
+	BlockRemoved
+	TypeChanged
+	MadeOptional
+	MadeRequired
+	DefaultChanged
+	EnumValueAdded
+	EnumValueRemoved
+	BlockRepeated
+	BlockNotRepeated
+	LabelsChanged
+	AliasAdded
+	AliasRemoved
+	Renamed
+	Deprecated
+)
+
+func (c ChangeKind) String() string {
+	switch c {
+	case AttributeAdded:
+		return "attribute added"
+	case AttributeRemoved:
+		return "attribute removed"
+	case BlockAdded:
+		return "block added"
+	case BlockRemoved:
+		return "block removed"
+	case TypeChanged:
+		return "type changed"
+	case MadeOptional:
+		return "made optional"
+	case MadeRequired:
+		return "made required"
+	case DefaultChanged:
+		return "default changed"
+	case EnumValueAdded:
+		return "enum value added"
+	case EnumValueRemoved:
+		return "enum value removed"
+	case BlockRepeated:
+		return "block became repeated"
+	case BlockNotRepeated:
+		return "block no longer repeated"
+	case LabelsChanged:
+		return "labels changed"
+	case AliasAdded:
+		return "alias added"
+	case AliasRemoved:
+		return "alias removed"
+	case Renamed:
+		return "renamed"
+	case Deprecated:
+		return "deprecated"
+	default:
+		return fmt.Sprintf("ChangeKind(%d)", int(c))
+	}
+}
+
+// Change is a difference between two schemas.
+type Change struct {
+	// Pos is the position of the entry in the new schema, or the old schema if
+	// it was removed.
+	Pos  Position
+	Kind ChangeKind
+	// Path is the dotted path of the attribute or block, eg. "service.port".
+	Path string
+	// Breaking is true if documents valid against the old schema may not be
+	// valid against the new schema.
+	Breaking bool
+	// Detail describes the change, eg. the old and new types.
+	Detail string
+}
+
+func (c Change) String() string {
+	w := &strings.Builder{}
+	if c.Pos.Line > 0 {
+		fmt.Fprintf(w, "%s: ", c.Pos)
+	}
+	if c.Breaking {
+		w.WriteString("breaking: ")
+	}
+	fmt.Fprintf(w, "%s %q", c.Kind, c.Path)
+	if c.Detail != "" {
+		fmt.Fprintf(w, ": %s", c.Detail)
+	}
+	return w.String()
+}
+
+// CompareSchemas classifies the differences between two schemas, such as
+// those produced by Schema for two versions of a configuration struct.
+//
+// A change is breaking if documents valid against before may not be valid
+// against after: attributes that are added as required, removed,
+// made required or change type, enum values that are removed, blocks that are
+// removed, are no longer repeated or change labels, and aliases that are
+// removed. Making attributes optional, adding optional attributes, blocks,
+// enum values or aliases, making blocks repeated, deprecating, renaming with
+// the old key as an alias and changing defaults are not breaking. Renamed
+// entries are compared with their old entries. Blocks referring to type definitions
+// are compared by their definitions.
+func CompareSchemas(before, after *AST) []Change {
+	c := &schemaComparer{
+		before: schemaDefinitions(before),
+		after:  schemaDefinitions(after),
+		seen:   map[[2]string]bool{},
+	}
+	return c.compareEntries("", withoutDefinitions(before.Entries, c.before), withoutDefinitions(after.Entries, c.after))
+}
+
+type schemaComparer struct {
+	before, after map[string]*Block
+	// seen pairs of old and new type definitions, to terminate recursion.
+	seen map[[2]string]bool
+}
+
+func (c *schemaComparer) compareEntries(path string, before, after []Entry) (changes []Change) {
+	afterEntries := map[string]Entry{}
+	for _, entry := range after {
+		if key := schemaEntryKey(entry); key != "" {
+			afterEntries[key] = entry
+		}
+	}
+	// Aliases match entries that were renamed, unless the key is still in use.
+	for _, entry := range after {
+		for _, alias := range schemaEntryAliases(entry) {
+			if _, ok := afterEntries[alias]; !ok {
+				afterEntries[alias] = entry
+			}
+		}
+	}
+	matched := map[Entry]bool{}
+	for _, entry := range before {
+		key := schemaEntryKey(entry)
+		if key == "" {
+			continue
+		}
+		if afterEntry, ok := afterEntries[key]; ok {
+			matched[afterEntry] = true
+		}
+		name := path + key
+		switch entry := entry.(type) {
+		case *Attribute:
+			afterAttr, ok := afterEntries[key].(*Attribute)
+			if !ok {
+				changes = append(changes, Change{Pos: entry.Pos, Kind: AttributeRemoved, Path: name, Breaking: true})
+				continue
+			}
+			changes = append(changes, compareAttributes(name, entry, afterAttr)...)
+
+		case *Block:
+			afterBlock, ok := afterEntries[key].(*Block)
+			if !ok {
+				changes = append(changes, Change{Pos: entry.Pos, Kind: BlockRemoved, Path: name, Breaking: true})
+				continue
+			}
+			changes = append(changes, c.compareBlocks(name, entry, afterBlock)...)
+		}
+	}
+	for _, entry := range after {
+		key := schemaEntryKey(entry)
+		if key == "" || matched[entry] {
+			continue
+		}
+		switch entry := entry.(type) {
+		case *Attribute:
+			required := !entry.Optional && entry.Default == nil
+			changes = append(changes, Change{Pos: entry.Pos, Kind: AttributeAdded, Path: path + key, Breaking: required})
+		case *Block:
+			changes = append(changes, Change{Pos: entry.Pos, Kind: BlockAdded, Path: path + key})
+		}
+	}
+	return changes
+}
+
+// schemaEntryKey returns the key of attributes and blocks, and "" for other entries.
+func schemaEntryKey(entry Entry) string {
+	switch entry := entry.(type) {
+	case *Attribute:
+		return entry.Key
+	case *Block:
+		return entry.Name
+	}
+	return ""
+}
+
+// schemaEntryAliases returns the aliases of attributes and blocks.
+func schemaEntryAliases(entry Entry) []string {
+	switch entry := entry.(type) {
+	case *Attribute:
+		return entry.Aliases
+	case *Block:
+		return entry.Aliases
+	}
+	return nil
+}
+
+// compareAliases reports renames and the aliases added and removed between
+// two entries, and their deprecation.
+func compareAliases(change func(kind ChangeKind, breaking bool, detail string), beforeKey, afterKey string, beforeAliases, afterAliases []string, beforeDeprecated, afterDeprecated string) {
+	beforeKeys := append([]string{beforeKey}, beforeAliases...)
+	afterKeys := append([]string{afterKey}, afterAliases...)
+	if beforeKey != afterKey {
+		change(Renamed, false, fmt.Sprintf("%s -> %s", beforeKey, afterKey))
+	}
+	for _, alias := range afterAliases {
+		if !contains(beforeKeys, alias) {
+			change(AliasAdded, false, alias)
+		}
+	}
+	for _, alias := range beforeAliases {
+		if !contains(afterKeys, alias) {
+			change(AliasRemoved, true, alias)
+		}
+	}
+	if beforeDeprecated == "" && afterDeprecated != "" {
+		change(Deprecated, false, afterDeprecated)
+	}
+}
+
+func compareAttributes(name string, before, after *Attribute) (changes []Change) {
+	change := func(kind ChangeKind, breaking bool, detail string) {
+		changes = append(changes, Change{Pos: after.Pos, Kind: kind, Path: name, Breaking: breaking, Detail: detail})
+	}
+	if beforeType, afterType := schemaString(before.Value), schemaString(after.Value); beforeType != afterType {
+		change(TypeChanged, true, fmt.Sprintf("%s -> %s", beforeType, afterType))
+	}
+	compareAliases(change, before.Key, after.Key, before.Aliases, after.Aliases, before.Deprecated, after.Deprecated)
+	beforeRequired := !before.Optional && before.Default == nil
+	afterRequired := !after.Optional && after.Default == nil
+	switch {
+	case beforeRequired && !afterRequired:
+		change(MadeOptional, false, "")
+	case !beforeRequired && afterRequired:
+		change(MadeRequired, true, "")
+	}
+	if beforeDefault, afterDefault := schemaString(before.Default), schemaString(after.Default); beforeDefault != afterDefault {
+		change(DefaultChanged, false, fmt.Sprintf("%s -> %s", beforeDefault, afterDefault))
+	}
+	beforeEnum := enumStrings(before.Enum)
+	afterEnum := enumStrings(after.Enum)
+	for _, value := range afterEnum {
+		// Adding an enum restricts previously unrestricted values.
+		if !contains(beforeEnum, value) {
+			change(EnumValueAdded, len(beforeEnum) == 0, value)
+		}
+	}
+	if len(afterEnum) > 0 {
+		for _, value := range beforeEnum {
+			if !contains(afterEnum, value) {
+				change(EnumValueRemoved, true, value)
+			}
+		}
+	}
+	return changes
+}
+
+func (c *schemaComparer) compareBlocks(name string, before, after *Block) (changes []Change) {
+	change := func(kind ChangeKind, breaking bool, detail string) {
+		changes = append(changes, Change{Pos: after.Pos, Kind: kind, Path: name, Breaking: breaking, Detail: detail})
+	}
+	switch {
+	case !before.Repeated && after.Repeated:
+		change(BlockRepeated, false, "")
+	case before.Repeated && !after.Repeated:
+		change(BlockNotRepeated, true, "")
+	}
+	compareAliases(change, before.Name, after.Name, before.Aliases, after.Aliases, before.Deprecated, after.Deprecated)
+	if len(before.Labels) != len(after.Labels) {
+		change(LabelsChanged, true, fmt.Sprintf("(%s) -> (%s)", strings.Join(before.Labels, ", "), strings.Join(after.Labels, ", ")))
+	}
+	if isRecursiveBlock(before) || isRecursiveBlock(after) {
+		return changes
+	}
+	if before.Ref != "" && after.Ref != "" {
+		key := [2]string{before.Ref, after.Ref}
+		if c.seen[key] {
+			return changes
+		}
+		c.seen[key] = true
+	}
+	beforeBody, afterBody := before.Body, after.Body
+	if definition, ok := c.before[before.Ref]; ok {
+		beforeBody = definition.Body
+	}
+	if definition, ok := c.after[after.Ref]; ok {
+		afterBody = definition.Body
+	}
+	return append(changes, c.compareEntries(name+".", beforeBody, afterBody)...)
+}
+
+func schemaString(value Value) string {
+	if value == nil {
+		return "none"
+	}
+	return value.String()
+}
+
+func enumStrings(values []Value) []string {
+	out := make([]string, len(values))
+	for i, value := range values {
+		out[i] = value.String()
+	}
+	return out
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
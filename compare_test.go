@@ -0,0 +1,113 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestCompareSchemas(t *testing.T) {
+	before, err := ParseString(`
+name = string
+port = number
+mode = string(enum("a", "b"))
+level = string(optional default("info"))
+removed = string(optional)
+tags = [string]
+
+server label {
+  host = string
+}
+
+listener(repeated) {
+  port = number
+}
+
+obsolete {
+  x = string
+}
+`)
+	assert.NoError(t, err)
+	after, err := ParseString(`
+name = string(optional)
+port = string
+mode = string(enum("a", "c"))
+level = string(optional default("debug"))
+tags = [string]
+region = string
+zone = string(optional)
+
+server(repeated) label {
+  host = string
+  timeout = number
+}
+
+listener {
+  port = number
+}
+
+added {
+}
+`)
+	assert.NoError(t, err)
+	actual := []string{}
+	for _, change := range CompareSchemas(before, after) {
+		actual = append(actual, change.String())
+	}
+	assert.Equal(t, []string{
+		`2:1: made optional "name"`,
+		`3:1: breaking: type changed "port": number -> string`,
+		`4:1: enum value added "mode": "c"`,
+		`4:1: breaking: enum value removed "mode": "b"`,
+		`5:1: default changed "level": "info" -> "debug"`,
+		`6:1: breaking: attribute removed "removed"`,
+		`10:1: block became repeated "server"`,
+		`12:3: breaking: attribute added "server.timeout"`,
+		`15:1: breaking: block no longer repeated "listener"`,
+		`17:1: breaking: block removed "obsolete"`,
+		`7:1: breaking: attribute added "region"`,
+		`8:1: attribute added "zone"`,
+		`19:1: block added "added"`,
+	}, actual)
+}
+
+func TestCompareSchemasReflected(t *testing.T) {
+	type oldConfig struct {
+		Name string `hcl:"name"`
+	}
+	type newConfig struct {
+		Name  string `hcl:"name"`
+		Level string `hcl:"level" enum:"debug,info" default:"info"`
+	}
+	changes := CompareSchemas(MustSchema(&oldConfig{}), MustSchema(&newConfig{}))
+	assert.Equal(t, []Change{{Kind: AttributeAdded, Path: "level"}}, changes)
+}
+
+func TestCompareSchemasAliases(t *testing.T) {
+	before, err := ParseString(`
+listen_addr = string
+port = number(aliases("p"))
+tls {
+  cert = string
+}
+`)
+	assert.NoError(t, err)
+	after, err := ParseString(`
+bind = string(aliases("listen_addr") deprecated("use bind"))
+port = number
+tls(aliases("ssl")) {
+  cert = string
+}
+`)
+	assert.NoError(t, err)
+	actual := []string{}
+	for _, change := range CompareSchemas(before, after) {
+		actual = append(actual, change.String())
+	}
+	assert.Equal(t, []string{
+		`2:1: renamed "listen_addr": listen_addr -> bind`,
+		`2:1: deprecated "listen_addr": use bind`,
+		`3:1: breaking: alias removed "port": p`,
+		`4:1: alias added "tls": ssl`,
+	}, actual)
+}
@@ -0,0 +1,477 @@
+// Package convert converts YAML and TOML documents to and from HCL ASTs.
+//
+// Documents are mapped structurally: mappings (tables in TOML) become blocks
+// or maps, sequences become lists or repeated blocks, and scalars become
+// attribute values. Comments are carried over where the format preserves them.
+//
+// Whether a mapping becomes a block or a map is ambiguous, so conversion can be
+// guided by a schema, either a Go type or an AST produced by hcl.Schema. Without
+// a schema, nested mappings become unlabelled blocks, sequences of mappings become
+// repeated blocks, and everything else becomes an attribute.
+//
+// Given a schema, block labels are taken from nested mapping keys, so the YAML:
+//
+//	service:
+//	  web:
+//	    port: 80
+//
+// converts to the following HCL if "service" is a block with one label:
+//
+//	service web {
+//	  port = 80
+//	}
+package convert
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+// Option configures conversion to HCL.
+type Option func(*config)
+
+type config struct {
+	schema   *hcl.AST
+	typ      interface{}
+	filename string
+}
+
+// WithSchema guides conversion using a schema AST, as produced by hcl.Schema
+// or parsed from a schema file.
+func WithSchema(schema *hcl.AST) Option {
+	return func(config *config) {
+		config.schema = schema
+	}
+}
+
+// WithType guides conversion using the schema of a Go type, which must be a
+// pointer to a struct.
+func WithType(v interface{}) Option {
+	return func(config *config) {
+		config.typ = v
+	}
+}
+
+// WithFilename sets the filename reported in positions and errors.
+func WithFilename(filename string) Option {
+	return func(config *config) {
+		config.filename = filename
+	}
+}
+
+func newConfig(options []Option) (*config, error) {
+	config := &config{}
+	for _, option := range options {
+		option(config)
+	}
+	if config.typ != nil {
+		schema, err := hcl.Schema(config.typ)
+		if err != nil {
+			return nil, err
+		}
+		config.schema = schema
+	}
+	return config, nil
+}
+
+func (c *config) wrapError(err error) error {
+	if c.filename == "" {
+		return err
+	}
+	return fmt.Errorf("%s: %w", c.filename, err)
+}
+
+type nodeKind int
+
+const (
+	scalarNode nodeKind = iota
+	sequenceNode
+	mappingNode
+)
+
+// node is the format-neutral document tree that YAML and TOML are converted
+// through.
+type node struct {
+	kind     nodeKind
+	pos      hcl.Position
+	comments []string
+	// Comment on the same line as the node.
+	lineComment string
+	// For scalarNode, one of *hcl.String, *hcl.Number or *hcl.Bool.
+	value hcl.Value
+	// For mappingNode, the keys of children.
+	keys     []string
+	children []*node
+	// For mappingNode and sequenceNode, whether this node was converted from
+	// blocks rather than a value.
+	block bool
+}
+
+func (n *node) add(key string, child *node) {
+	n.keys = append(n.keys, key)
+	n.children = append(n.children, child)
+}
+
+func (n *node) get(key string) *node {
+	for i, k := range n.keys {
+		if k == key {
+			return n.children[i]
+		}
+	}
+	return nil
+}
+
+// toAST converts a mapping node to an AST.
+func (c *config) toAST(root *node) (*hcl.AST, error) {
+	if root.kind != mappingNode {
+		return nil, participle.Errorf(root.pos, "expected a mapping at the top level")
+	}
+	var schema []hcl.Entry
+	if c.schema != nil {
+		schema = c.schema.Entries
+	}
+	entries, err := toEntries(root, schema)
+	if err != nil {
+		return nil, err
+	}
+	ast := &hcl.AST{Pos: root.pos, Entries: entries}
+	if err := hcl.AddParentRefs(ast); err != nil {
+		return nil, err
+	}
+	return ast, nil
+}
+
+func toEntries(n *node, schema []hcl.Entry) (hcl.Entries, error) {
+	entries := hcl.Entries{}
+	for i, key := range n.keys {
+		child := n.children[i]
+		var (
+			blockSchema *hcl.Block
+			isAttr      bool
+		)
+		for _, entry := range schema {
+			switch entry := entry.(type) {
+			case *hcl.Block:
+				if entry.Name == key {
+					blockSchema = entry
+				}
+			case *hcl.Attribute:
+				isAttr = isAttr || entry.Key == key
+			}
+		}
+		switch {
+		case blockSchema != nil:
+			blocks, err := toBlocks(key, child, len(blockSchema.Labels), nil, blockSchema.Body)
+			if err != nil {
+				return nil, err
+			}
+			attachComments(blocks[0], child)
+			for _, block := range blocks {
+				entries = append(entries, block)
+			}
+
+		case !isAttr && (child.kind == mappingNode || isBlockSequence(child)):
+			blocks, err := toBlocks(key, child, 0, nil, nil)
+			if err != nil {
+				return nil, err
+			}
+			attachComments(blocks[0], child)
+			for _, block := range blocks {
+				entries = append(entries, block)
+			}
+
+		default:
+			value, err := toValue(child)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, &hcl.Attribute{
+				Pos:         child.pos,
+				Comments:    child.comments,
+				Key:         key,
+				Value:       value,
+				LineComment: child.lineComment,
+			})
+		}
+	}
+	return entries, nil
+}
+
+func isBlockSequence(n *node) bool {
+	if n.kind != sequenceNode || len(n.children) == 0 {
+		return false
+	}
+	for _, child := range n.children {
+		if child.kind != mappingNode {
+			return false
+		}
+	}
+	return true
+}
+
+// toBlocks converts n into blocks, consuming a level of mapping keys for each
+// of the remaining labels.
+func toBlocks(name string, n *node, labels int, prefix []string, schema []hcl.Entry) ([]*hcl.Block, error) {
+	switch {
+	case n.kind == sequenceNode:
+		var blocks []*hcl.Block
+		for _, child := range n.children {
+			childBlocks, err := toBlocks(name, child, labels, prefix, schema)
+			if err != nil {
+				return nil, err
+			}
+			attachComments(childBlocks[0], child)
+			blocks = append(blocks, childBlocks...)
+		}
+		if len(blocks) == 0 {
+			return nil, participle.Errorf(n.pos, "expected at least one %q block", name)
+		}
+		return blocks, nil
+
+	case n.kind != mappingNode:
+		return nil, participle.Errorf(n.pos, "expected a mapping for block %q", name)
+
+	case labels > 0:
+		var blocks []*hcl.Block
+		for i, label := range n.keys {
+			labelBlocks, err := toBlocks(name, n.children[i], labels-1, append(prefix[:len(prefix):len(prefix)], label), schema)
+			if err != nil {
+				return nil, err
+			}
+			attachComments(labelBlocks[0], n.children[i])
+			blocks = append(blocks, labelBlocks...)
+		}
+		if len(blocks) == 0 {
+			return nil, participle.Errorf(n.pos, "expected labels for block %q", name)
+		}
+		return blocks, nil
+
+	default:
+		body, err := toEntries(n, schema)
+		if err != nil {
+			return nil, err
+		}
+		return []*hcl.Block{{
+			Pos:    n.pos,
+			Name:   name,
+			Labels: prefix,
+			Body:   body,
+		}}, nil
+	}
+}
+
+func toValue(n *node) (hcl.Value, error) {
+	switch n.kind {
+	case scalarNode:
+		return n.value, nil
+
+	case sequenceNode:
+		list := &hcl.List{Pos: n.pos, List: []hcl.Value{}}
+		for _, child := range n.children {
+			value, err := toValue(child)
+			if err != nil {
+				return nil, err
+			}
+			list.List = append(list.List, value)
+			if len(child.comments) > 0 || child.lineComment != "" {
+				list.Elements = append(list.Elements, &hcl.ListElement{
+					Pos:         child.pos,
+					Comments:    child.comments,
+					Value:       value,
+					LineComment: child.lineComment,
+				})
+			}
+		}
+		return list, nil
+
+	default:
+		m := &hcl.Map{Pos: n.pos}
+		for i, key := range n.keys {
+			child := n.children[i]
+			value, err := toValue(child)
+			if err != nil {
+				return nil, err
+			}
+			m.Entries = append(m.Entries, &hcl.MapEntry{
+				Pos:         child.pos,
+				Comments:    child.comments,
+				Key:         &hcl.String{Pos: child.pos, Str: key},
+				Value:       value,
+				LineComment: child.lineComment,
+			})
+		}
+		return m, nil
+	}
+}
+
+// fromAST converts an AST to a mapping node.
+//
+// Labelled blocks become nested mappings keyed by label, and blocks sharing
+// the same name and labels become sequences.
+func fromAST(ast *hcl.AST) (*node, error) {
+	return fromEntries(ast.Entries)
+}
+
+func fromEntries(entries []hcl.Entry) (*node, error) {
+	out := &node{kind: mappingNode, block: true}
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case *hcl.Attribute:
+			if out.get(entry.Key) != nil {
+				return nil, participle.Errorf(entry.Pos, "duplicate key %q", entry.Key)
+			}
+			value, err := fromValue(entry.Value)
+			if err != nil {
+				return nil, err
+			}
+			value.comments = entry.Comments
+			value.lineComment = entry.LineComment
+			out.add(entry.Key, value)
+
+		case *hcl.Block:
+			body, err := fromEntries(entry.Body)
+			if err != nil {
+				return nil, err
+			}
+			body.comments = entry.Comments
+			body.lineComment = entry.LineComment
+			if body.lineComment == "" {
+				body.lineComment = entry.EndComment
+			}
+			parent := out
+			path := append([]string{entry.Name}, entry.Labels...)
+			for _, key := range path[:len(path)-1] {
+				next := parent.get(key)
+				if next == nil {
+					next = &node{kind: mappingNode, block: true}
+					parent.add(key, next)
+				} else if next.kind != mappingNode || !next.block {
+					return nil, participle.Errorf(entry.Pos, "%s cannot be both block and attribute", key)
+				}
+				parent = next
+			}
+			key := path[len(path)-1]
+			existing := parent.get(key)
+			switch {
+			case existing == nil:
+				parent.add(key, body)
+			case !existing.block:
+				return nil, participle.Errorf(entry.Pos, "%s cannot be both block and attribute", key)
+			case existing.kind == sequenceNode:
+				existing.children = append(existing.children, body)
+			default:
+				// Replace the single block with a sequence of blocks.
+				for i, k := range parent.keys {
+					if k == key {
+						parent.children[i] = &node{kind: sequenceNode, block: true, children: []*node{existing, body}}
+					}
+				}
+			}
+		}
+	}
+	return out, nil
+}
+
+func fromValue(value hcl.Value) (*node, error) {
+	switch value := value.(type) {
+	case nil:
+		// Bare attribute.
+		return &node{kind: scalarNode, value: &hcl.Bool{Bool: true}}, nil
+
+	case *hcl.String, *hcl.Number, *hcl.Bool:
+		return &node{kind: scalarNode, pos: value.Position(), value: value}, nil
+
+	case *hcl.Heredoc:
+		return &node{kind: scalarNode, pos: value.Pos, value: &hcl.String{Pos: value.Pos, Str: value.GetHeredoc()}}, nil
+
+	case *hcl.Type:
+		return &node{kind: scalarNode, pos: value.Pos, value: &hcl.String{Pos: value.Pos, Str: value.Type}}, nil
+
+	case *hcl.List:
+		out := &node{kind: sequenceNode, pos: value.Pos}
+		for _, el := range value.List {
+			child, err := fromValue(el)
+			if err != nil {
+				return nil, err
+			}
+			for _, element := range value.Elements {
+				if element.Value == el {
+					child.comments = element.Comments
+					child.lineComment = element.LineComment
+				}
+			}
+			out.children = append(out.children, child)
+		}
+		return out, nil
+
+	case *hcl.Map:
+		out := &node{kind: mappingNode, pos: value.Pos}
+		for _, entry := range value.Entries {
+			key := entry.Key.String()
+			if str, ok := entry.Key.(*hcl.String); ok {
+				key = str.Str
+			}
+			child, err := fromValue(entry.Value)
+			if err != nil {
+				return nil, err
+			}
+			child.comments = entry.Comments
+			child.lineComment = entry.LineComment
+			out.add(key, child)
+		}
+		return out, nil
+
+	default:
+		return nil, participle.Errorf(value.Position(), "can't convert %T", value)
+	}
+}
+
+// attachComments prepends the comments of n to those of block, and sets the
+// line comment of the block if it has none.
+func attachComments(block *hcl.Block, n *node) {
+	if len(n.comments) > 0 {
+		block.Comments = append(append(hcl.CommentList{}, n.comments...), block.Comments...)
+	}
+	if block.LineComment == "" {
+		block.LineComment = n.lineComment
+	}
+}
+
+// numberText formats an HCL number as an integer if possible.
+func numberText(n *hcl.Number) string {
+	if n.Float.IsInt() {
+		return n.Float.Text('f', 0)
+	}
+	return n.Float.Text('g', -1)
+}
+
+func newNumber(pos hcl.Position, s string) (*hcl.Number, error) {
+	f, _, err := big.ParseFloat(s, 0, 0, big.ToNearestEven)
+	if err != nil {
+		return nil, participle.Wrapf(pos, err, "invalid number %q", s)
+	}
+	return &hcl.Number{Pos: pos, Float: f}, nil
+}
+
+// splitComment splits a comment block into lines, stripping comment markers.
+func splitComment(comment string) []string {
+	if comment == "" {
+		return nil
+	}
+	lines := strings.Split(comment, "\n")
+	out := make([]string, 0, len(lines))
+	for _, line := range lines {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		line = strings.TrimPrefix(line, "#")
+		out = append(out, strings.TrimPrefix(line, " "))
+	}
+	return out
+}
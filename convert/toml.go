@@ -0,0 +1,451 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"math/big"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+// FromTOML converts a TOML document to an HCL AST.
+//
+// Key order is preserved. Comments on the lines preceding a key or table
+// header, and trailing comments on the same line as a key, are carried into
+// the Comments of the corresponding attribute or block. TOML values carry no
+// positions, so AST nodes are not positioned.
+func FromTOML(data []byte, options ...Option) (*hcl.AST, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	doc := map[string]interface{}{}
+	md, err := toml.Decode(string(data), &doc)
+	if err != nil {
+		return nil, config.wrapError(err)
+	}
+	t := &tomlDecoder{
+		order:    map[string]int{},
+		comments: scanTOMLComments(string(data)),
+	}
+	for i, key := range md.Keys() {
+		// Implicitly defined tables are ordered by their first descendant.
+		for j := range key {
+			if _, ok := t.order[key[:j+1].String()]; !ok {
+				t.order[key[:j+1].String()] = i
+			}
+		}
+	}
+	root, err := t.fromValue(nil, doc)
+	if err != nil {
+		return nil, config.wrapError(err)
+	}
+	return config.toAST(root)
+}
+
+// ToTOML converts an HCL AST to a TOML document.
+//
+// Blocks become tables, repeated blocks become arrays of tables and maps become
+// inline tables. As TOML requires, attributes are written before the blocks
+// of the same body.
+func ToTOML(ast *hcl.AST) ([]byte, error) {
+	root, err := fromAST(ast)
+	if err != nil {
+		return nil, err
+	}
+	w := &bytes.Buffer{}
+	writeTOMLTable(w, nil, root)
+	return w.Bytes(), nil
+}
+
+type tomlDecoder struct {
+	// Index of each key in document order.
+	order map[string]int
+	// Comments for each occurrence of a key, in document order.
+	comments map[string][]tomlComments
+}
+
+type tomlComments struct {
+	// Comment lines preceding the key.
+	lines []string
+	// Comment on the same line as the key.
+	line string
+}
+
+// popComments sets the comments of n to those of the next occurrence of key.
+func (t *tomlDecoder) popComments(n *node, key string) {
+	queue := t.comments[key]
+	if len(queue) == 0 {
+		return
+	}
+	t.comments[key] = queue[1:]
+	n.comments = queue[0].lines
+	n.lineComment = queue[0].line
+}
+
+func (t *tomlDecoder) fromValue(path []string, v interface{}) (*node, error) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		for key := range v {
+			keys = append(keys, key)
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			return t.order[tomlChildKey(path, keys[i])] < t.order[tomlChildKey(path, keys[j])]
+		})
+		out := &node{kind: mappingNode}
+		for _, key := range keys {
+			childPath := append(path[:len(path):len(path)], key)
+			child, err := t.fromValue(childPath, v[key])
+			if err != nil {
+				return nil, err
+			}
+			if child.kind != sequenceNode || !child.block {
+				t.popComments(child, toml.Key(childPath).String())
+			}
+			out.add(key, child)
+		}
+		return out, nil
+
+	case []map[string]interface{}:
+		// Array of tables.
+		out := &node{kind: sequenceNode, block: true}
+		key := toml.Key(path).String()
+		for _, table := range v {
+			comments := &node{}
+			t.popComments(comments, key)
+			child, err := t.fromValue(path, table)
+			if err != nil {
+				return nil, err
+			}
+			child.comments, child.lineComment = comments.comments, comments.lineComment
+			out.children = append(out.children, child)
+		}
+		return out, nil
+
+	case []interface{}:
+		out := &node{kind: sequenceNode}
+		for _, el := range v {
+			child, err := t.fromValue(path, el)
+			if err != nil {
+				return nil, err
+			}
+			out.children = append(out.children, child)
+		}
+		return out, nil
+
+	case string:
+		return &node{value: &hcl.String{Str: v}}, nil
+
+	case int64:
+		return &node{value: &hcl.Number{Float: big.NewFloat(0).SetInt64(v)}}, nil
+
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return nil, fmt.Errorf("%s: %v is not supported", toml.Key(path), v)
+		}
+		return &node{value: &hcl.Number{Float: big.NewFloat(v)}}, nil
+
+	case bool:
+		return &node{value: &hcl.Bool{Bool: v}}, nil
+
+	case time.Time:
+		return &node{value: &hcl.String{Str: v.Format(time.RFC3339Nano)}}, nil
+
+	default:
+		// Local dates and times.
+		return &node{value: &hcl.String{Str: fmt.Sprint(v)}}, nil
+	}
+}
+
+func tomlChildKey(path []string, key string) string {
+	return toml.Key(append(path[:len(path):len(path)], key)).String()
+}
+
+// scanTOMLComments associates comments with the keys and table headers they
+// annotate, as TOML decoders discard them.
+//
+// Comment lines immediately preceding a key or header, along with a trailing
+// comment on the same line, are recorded for each occurrence of the key.
+func scanTOMLComments(data string) map[string][]tomlComments {
+	out := map[string][]tomlComments{}
+	var (
+		table     []string
+		pending   []string
+		depth     int
+		multiline string
+	)
+	record := func(path []string, comment string) {
+		key := toml.Key(path).String()
+		out[key] = append(out[key], tomlComments{lines: pending, line: comment})
+		pending = nil
+	}
+	for _, line := range strings.Split(data, "\n") {
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case multiline != "":
+			if strings.Count(line, multiline)%2 == 1 {
+				multiline = ""
+			}
+
+		case depth > 0:
+			lineDepth, _ := scanTOMLValue(trimmed)
+			depth += lineDepth
+
+		case trimmed == "":
+			pending = nil
+
+		case strings.HasPrefix(trimmed, "#"):
+			pending = append(pending, strings.TrimPrefix(strings.TrimPrefix(trimmed, "#"), " "))
+
+		case strings.HasPrefix(trimmed, "["):
+			path, rest := parseTOMLKey(strings.TrimLeft(trimmed, "["))
+			table = path
+			_, comment := scanTOMLValue(strings.TrimLeft(rest, "]"))
+			record(path, comment)
+
+		default:
+			path, rest := parseTOMLKey(trimmed)
+			value := strings.TrimPrefix(strings.TrimSpace(rest), "=")
+			for _, delimiter := range []string{`"""`, `'''`} {
+				if strings.Count(value, delimiter)%2 == 1 {
+					multiline = delimiter
+				}
+			}
+			comment := ""
+			if multiline == "" {
+				depth, comment = scanTOMLValue(value)
+			}
+			record(append(table[:len(table):len(table)], path...), comment)
+		}
+	}
+	return out
+}
+
+// scanTOMLValue returns the change in bracket nesting over a (partial) TOML
+// value, and any trailing comment.
+func scanTOMLValue(s string) (depth int, comment string) {
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case quote != 0:
+			if c == '\\' && quote == '"' {
+				i++
+			} else if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		case c == '#':
+			return depth, strings.TrimPrefix(strings.TrimSpace(s[i+1:]), " ")
+		}
+	}
+	return depth, ""
+}
+
+var tomlBareKeyRe = regexp.MustCompile(`^[A-Za-z0-9_-]+`)
+
+// parseTOMLKey parses a possibly dotted and quoted key, returning its parts and
+// the remainder of s.
+func parseTOMLKey(s string) (path []string, rest string) {
+	for {
+		s = strings.TrimLeft(s, " \t")
+		switch {
+		case strings.HasPrefix(s, `"`):
+			end := 1
+			for end < len(s) && s[end] != '"' {
+				if s[end] == '\\' {
+					end++
+				}
+				end++
+			}
+			if end >= len(s) {
+				return path, ""
+			}
+			key, err := strconv.Unquote(s[:end+1])
+			if err != nil {
+				key = s[1:end]
+			}
+			path = append(path, key)
+			s = s[end+1:]
+
+		case strings.HasPrefix(s, "'"):
+			end := strings.IndexByte(s[1:], '\'')
+			if end < 0 {
+				return path, ""
+			}
+			path = append(path, s[1:end+1])
+			s = s[end+2:]
+
+		default:
+			key := tomlBareKeyRe.FindString(s)
+			if key == "" {
+				return path, s
+			}
+			path = append(path, key)
+			s = s[len(key):]
+		}
+		s = strings.TrimLeft(s, " \t")
+		if !strings.HasPrefix(s, ".") {
+			return path, s
+		}
+		s = s[1:]
+	}
+}
+
+func isTOMLTable(n *node) bool {
+	return n.block && n.kind != scalarNode
+}
+
+func writeTOMLTable(w *bytes.Buffer, path []string, n *node) {
+	// Plain keys must precede sub-tables.
+	for i, key := range n.keys {
+		child := n.children[i]
+		if isTOMLTable(child) {
+			continue
+		}
+		writeTOMLComments(w, child.comments)
+		fmt.Fprintf(w, "%s = ", tomlKey(key))
+		writeTOMLValue(w, child)
+		writeTOMLLineComment(w, child.lineComment)
+		fmt.Fprintln(w)
+	}
+	for i, key := range n.keys {
+		child := n.children[i]
+		if !isTOMLTable(child) {
+			continue
+		}
+		childPath := append(path[:len(path):len(path)], key)
+		if child.kind == sequenceNode {
+			for _, el := range child.children {
+				writeTOMLHeader(w, "[[%s]]", childPath, el)
+				writeTOMLTable(w, childPath, el)
+			}
+			continue
+		}
+		// Omit headers of tables that are implied by their sub-tables.
+		implied := len(child.keys) > 0 && len(child.comments) == 0 && child.lineComment == ""
+		for _, grandchild := range child.children {
+			implied = implied && isTOMLTable(grandchild)
+		}
+		if !implied {
+			writeTOMLHeader(w, "[%s]", childPath, child)
+		}
+		writeTOMLTable(w, childPath, child)
+	}
+}
+
+func writeTOMLHeader(w *bytes.Buffer, format string, path []string, table *node) {
+	if w.Len() > 0 {
+		fmt.Fprintln(w)
+	}
+	writeTOMLComments(w, table.comments)
+	keys := make([]string, len(path))
+	for i, key := range path {
+		keys[i] = tomlKey(key)
+	}
+	fmt.Fprintf(w, format, strings.Join(keys, "."))
+	writeTOMLLineComment(w, table.lineComment)
+	fmt.Fprintln(w)
+}
+
+func writeTOMLComments(w *bytes.Buffer, comments []string) {
+	for _, comment := range comments {
+		fmt.Fprintf(w, "# %s\n", comment)
+	}
+}
+
+func writeTOMLLineComment(w *bytes.Buffer, comment string) {
+	if comment != "" {
+		fmt.Fprintf(w, " # %s", comment)
+	}
+}
+
+func writeTOMLValue(w *bytes.Buffer, n *node) {
+	switch n.kind {
+	case sequenceNode:
+		w.WriteString("[")
+		for i, child := range n.children {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			writeTOMLValue(w, child)
+		}
+		w.WriteString("]")
+
+	case mappingNode:
+		if len(n.keys) == 0 {
+			w.WriteString("{}")
+			return
+		}
+		w.WriteString("{ ")
+		for i, key := range n.keys {
+			if i > 0 {
+				w.WriteString(", ")
+			}
+			fmt.Fprintf(w, "%s = ", tomlKey(key))
+			writeTOMLValue(w, n.children[i])
+		}
+		w.WriteString(" }")
+
+	default:
+		switch value := n.value.(type) {
+		case *hcl.Number:
+			w.WriteString(numberText(value))
+		case *hcl.Bool:
+			fmt.Fprint(w, value.Bool)
+		default:
+			w.WriteString(tomlQuote(value.(*hcl.String).Str))
+		}
+	}
+}
+
+func tomlKey(key string) string {
+	if key != "" && tomlBareKeyRe.FindString(key) == key {
+		return key
+	}
+	return tomlQuote(key)
+}
+
+func tomlQuote(s string) string {
+	out := &strings.Builder{}
+	out.WriteByte('"')
+	for _, r := range s {
+		switch r {
+		case '"', '\\':
+			out.WriteByte('\\')
+			out.WriteRune(r)
+		case '\b':
+			out.WriteString(`\b`)
+		case '\t':
+			out.WriteString(`\t`)
+		case '\n':
+			out.WriteString(`\n`)
+		case '\f':
+			out.WriteString(`\f`)
+		case '\r':
+			out.WriteString(`\r`)
+		default:
+			if r < 0x20 || r == 0x7f {
+				fmt.Fprintf(out, `\u%04X`, r)
+			} else {
+				out.WriteRune(r)
+			}
+		}
+	}
+	out.WriteByte('"')
+	return out.String()
+}
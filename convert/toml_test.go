@@ -0,0 +1,134 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+const tomlSource = `# Schema version.
+version = 2
+labels = { env = "prod" }
+
+# The web frontend.
+[service.web]
+port = 80 # HTTP
+hosts = [
+  "a", # first
+  "b",
+]
+
+[service.db]
+port = 5432
+`
+
+func TestFromTOML(t *testing.T) {
+	ast, err := FromTOML([]byte(tomlSource), WithType(&yamlConfig{}))
+	assert.NoError(t, err)
+	data, err := hcl.MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `// Schema version.
+version = 2
+labels = {
+  "env": "prod",
+}
+
+// The web frontend.
+service web {
+  port = 80 // HTTP
+  hosts = ["a", "b"]
+}
+
+service db {
+  port = 5432
+}
+`, string(data))
+}
+
+func TestFromTOMLArrayOfTables(t *testing.T) {
+	ast, err := FromTOML([]byte(`
+# First.
+[[rule]]
+name = "a"
+
+[[rule]]
+# Second name.
+name = """
+b
+"""
+`))
+	assert.NoError(t, err)
+	data, err := hcl.MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `// First.
+rule {
+  name = "a"
+}
+
+rule {
+  // Second name.
+  name = "b\n"
+}
+`, string(data))
+}
+
+func TestFromTOMLError(t *testing.T) {
+	_, err := FromTOML([]byte("a = \n"), WithFilename("config.toml"))
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "config.toml: toml: line 2")
+}
+
+func TestTOMLRoundTrip(t *testing.T) {
+	ast, err := FromTOML([]byte(tomlSource), WithType(&yamlConfig{}))
+	assert.NoError(t, err)
+	data, err := ToTOML(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `# Schema version.
+version = 2
+labels = { env = "prod" }
+
+# The web frontend.
+[service.web]
+port = 80 # HTTP
+hosts = ["a", "b"]
+
+[service.db]
+port = 5432
+`, string(data))
+}
+
+func TestToTOML(t *testing.T) {
+	ast, err := hcl.ParseString(`
+		name = "a \"quoted\"\tstring"
+		ratio = 0.5
+
+		// Rules.
+		rule {
+			allow = true
+			nested {
+				key = "value"
+			}
+		}
+		rule {
+			allow = false
+		}
+	`)
+	assert.NoError(t, err)
+	data, err := ToTOML(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `name = "a \"quoted\"\tstring"
+ratio = 0.5
+
+# Rules.
+[[rule]]
+allow = true
+
+[rule.nested]
+key = "value"
+
+[[rule]]
+allow = false
+`, string(data))
+}
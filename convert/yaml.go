@@ -0,0 +1,183 @@
+package convert
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"gopkg.in/yaml.v3"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+// FromYAML converts a YAML document to an HCL AST.
+//
+// Head comments are carried into the Comments, and line comments into the
+// LineComment, of the corresponding attribute, block, map entry or list element.
+func FromYAML(data []byte, options ...Option) (*hcl.AST, error) {
+	config, err := newConfig(options)
+	if err != nil {
+		return nil, err
+	}
+	doc := &yaml.Node{}
+	if err := yaml.Unmarshal(data, doc); err != nil {
+		return nil, config.wrapError(err)
+	}
+	if len(doc.Content) == 0 {
+		return &hcl.AST{}, nil
+	}
+	root, err := config.fromYAMLNode(doc.Content[0])
+	if err != nil {
+		return nil, err
+	}
+	return config.toAST(root)
+}
+
+// ToYAML converts an HCL AST to a YAML document.
+func ToYAML(ast *hcl.AST) ([]byte, error) {
+	root, err := fromAST(ast)
+	if err != nil {
+		return nil, err
+	}
+	w := &bytes.Buffer{}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(toYAMLNode(root)); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return w.Bytes(), nil
+}
+
+func (c *config) fromYAMLNode(y *yaml.Node) (*node, error) {
+	if y.Kind == yaml.AliasNode {
+		return c.fromYAMLNode(y.Alias)
+	}
+	n := &node{pos: hcl.Position{Filename: c.filename, Line: y.Line, Column: y.Column}}
+	switch y.Kind {
+	case yaml.MappingNode:
+		n.kind = mappingNode
+		for i := 0; i+1 < len(y.Content); i += 2 {
+			key, value := y.Content[i], y.Content[i+1]
+			child, err := c.fromYAMLNode(value)
+			if err != nil {
+				return nil, err
+			}
+			child.comments = splitComment(key.HeadComment)
+			child.lineComment = yamlLineComment(key.LineComment, value.LineComment)
+			n.add(key.Value, child)
+		}
+
+	case yaml.SequenceNode:
+		n.kind = sequenceNode
+		for _, value := range y.Content {
+			child, err := c.fromYAMLNode(value)
+			if err != nil {
+				return nil, err
+			}
+			child.comments = splitComment(value.HeadComment)
+			child.lineComment = yamlLineComment(value.LineComment)
+			n.children = append(n.children, child)
+		}
+
+	case yaml.ScalarNode:
+		n.kind = scalarNode
+		switch y.ShortTag() {
+		case "!!null":
+			return nil, participle.Errorf(n.pos, "null values are not supported")
+		case "!!int", "!!float":
+			number, err := newNumber(n.pos, y.Value)
+			if err != nil {
+				return nil, err
+			}
+			n.value = number
+		case "!!bool":
+			var b bool
+			if err := y.Decode(&b); err != nil {
+				return nil, participle.Wrapf(n.pos, err, "invalid boolean")
+			}
+			n.value = &hcl.Bool{Pos: n.pos, Bool: b}
+		default:
+			n.value = &hcl.String{Pos: n.pos, Str: y.Value}
+		}
+
+	default:
+		return nil, participle.Errorf(n.pos, "unsupported YAML node")
+	}
+	return n, nil
+}
+
+func toYAMLNode(n *node) *yaml.Node {
+	switch n.kind {
+	case mappingNode:
+		out := &yaml.Node{Kind: yaml.MappingNode}
+		for i, key := range n.keys {
+			child := n.children[i]
+			keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key, HeadComment: yamlComment(child.comments)}
+			valueNode := toYAMLNode(child)
+			if child.kind == scalarNode {
+				valueNode.LineComment = yamlComment(lineComments(child))
+			} else {
+				keyNode.LineComment = yamlComment(lineComments(child))
+			}
+			out.Content = append(out.Content, keyNode, valueNode)
+		}
+		return out
+
+	case sequenceNode:
+		out := &yaml.Node{Kind: yaml.SequenceNode}
+		for _, child := range n.children {
+			el := toYAMLNode(child)
+			el.HeadComment = yamlComment(child.comments)
+			if child.kind == scalarNode {
+				el.LineComment = yamlComment(lineComments(child))
+			}
+			out.Content = append(out.Content, el)
+		}
+		return out
+
+	default:
+		switch value := n.value.(type) {
+		case *hcl.Number:
+			tag := "!!float"
+			if value.Float.IsInt() {
+				tag = "!!int"
+			}
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: numberText(value)}
+		case *hcl.Bool:
+			return &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!bool", Value: fmt.Sprint(value.Bool)}
+		default:
+			out := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: value.(*hcl.String).Str}
+			if strings.Contains(out.Value, "\n") {
+				out.Style = yaml.LiteralStyle
+			}
+			return out
+		}
+	}
+}
+
+// yamlLineComment joins YAML line comments, stripping their markers.
+func yamlLineComment(comments ...string) string {
+	out := []string{}
+	for _, comment := range comments {
+		out = append(out, splitComment(comment)...)
+	}
+	return strings.Join(out, " ")
+}
+
+func lineComments(n *node) []string {
+	if n.lineComment == "" {
+		return nil
+	}
+	return []string{n.lineComment}
+}
+
+func yamlComment(lines []string) string {
+	if len(lines) == 0 {
+		return ""
+	}
+	return "# " + strings.Join(lines, "\n# ")
+}
@@ -0,0 +1,171 @@
+package convert
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+type yamlService struct {
+	Name     string   `hcl:"name,label"`
+	Port     int      `hcl:"port"`
+	Hosts    []string `hcl:"hosts,optional"`
+	Replicas int      `hcl:"replicas,optional"`
+}
+
+type yamlConfig struct {
+	Version  int               `hcl:"version"`
+	Labels   map[string]string `hcl:"labels,optional"`
+	Services []yamlService     `hcl:"service,block"`
+}
+
+const yamlSource = `# Schema version.
+version: 2
+labels:
+  env: prod
+service:
+  # The web frontend.
+  web:
+    port: 80 # HTTP
+    hosts: [a, b]
+  db:
+    port: 5432
+`
+
+func TestFromYAML(t *testing.T) {
+	ast, err := FromYAML([]byte(yamlSource), WithType(&yamlConfig{}))
+	assert.NoError(t, err)
+	data, err := hcl.MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `// Schema version.
+version = 2
+labels = {
+  "env": "prod",
+}
+
+// The web frontend.
+service web {
+  port = 80 // HTTP
+  hosts = ["a", "b"]
+}
+
+service db {
+  port = 5432
+}
+`, string(data))
+
+	config := &yamlConfig{}
+	err = hcl.UnmarshalAST(ast, config)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(config.Services))
+}
+
+func TestFromYAMLWithoutSchema(t *testing.T) {
+	ast, err := FromYAML([]byte(`
+server:
+  port: 80
+rule:
+  - name: a
+  - name: b
+tags: [x, y]
+`))
+	assert.NoError(t, err)
+	data, err := hcl.MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `server {
+  port = 80
+}
+
+rule {
+  name = "a"
+}
+
+rule {
+  name = "b"
+}
+
+tags = ["x", "y"]
+`, string(data))
+}
+
+func TestFromYAMLErrors(t *testing.T) {
+	_, err := FromYAML([]byte("a:\n  b: ~\n"), WithFilename("config.yaml"))
+	assert.EqualError(t, err, "config.yaml:2:6: null values are not supported")
+
+	_, err = FromYAML([]byte("- a\n"))
+	assert.EqualError(t, err, "1:1: expected a mapping at the top level")
+
+	_, err = FromYAML([]byte("service: 1\n"), WithType(&yamlConfig{}))
+	assert.EqualError(t, err, `1:10: expected a mapping for block "service"`)
+}
+
+func TestYAMLRoundTrip(t *testing.T) {
+	ast, err := FromYAML([]byte(yamlSource), WithType(&yamlConfig{}))
+	assert.NoError(t, err)
+	data, err := ToYAML(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `# Schema version.
+version: 2
+labels:
+  env: prod
+service:
+  # The web frontend.
+  web:
+    port: 80 # HTTP
+    hosts:
+      - a
+      - b
+  db:
+    port: 5432
+`, string(data))
+}
+
+func TestToYAMLRepeatedBlocks(t *testing.T) {
+	ast, err := hcl.ParseString(`
+		// First.
+		rule { name = "a" }
+		rule { name = "b" }
+		doc = <<EOF
+line 1
+line 2
+EOF
+	`)
+	assert.NoError(t, err)
+	data, err := ToYAML(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `rule:
+  # First.
+  - name: a
+  - name: b
+doc: |-
+  line 1
+  line 2
+`, string(data))
+}
+
+func TestYAMLListComments(t *testing.T) {
+	ast, err := FromYAML([]byte(`
+hosts:
+  - a # first
+  # Second.
+  - b
+`))
+	assert.NoError(t, err)
+	data, err := hcl.MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `hosts = [
+  "a", // first
+  // Second.
+  "b",
+]
+`, string(data))
+	out, err := ToYAML(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `hosts:
+  - a # first
+  # Second.
+  - b
+`, string(out))
+}
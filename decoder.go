@@ -0,0 +1,210 @@
+package hcl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"regexp"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// A Decoder reads top-level blocks from an input stream one at a time.
+//
+// Only the source of the block currently being decoded is held in memory, so
+// arbitrarily large or concatenated inputs can be processed. Top-level
+// attributes are not supported, and comments not attached to a block are
+// discarded.
+type Decoder struct {
+	r       *bufio.Reader
+	config  *parseConfig
+	line    int
+	offset  int
+	pending []*Block
+}
+
+// NewDecoder creates a Decoder reading from r.
+//
+// WithLossless is not supported by a Decoder and is ignored.
+func NewDecoder(r io.Reader, options ...ParseOption) *Decoder {
+	config := &parseConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	config.lossless = false
+	return &Decoder{r: bufio.NewReader(r), config: config, line: 1}
+}
+
+// Next returns the next top-level block, or io.EOF if there are no more.
+func (d *Decoder) Next() (*Block, error) {
+	for len(d.pending) == 0 {
+		source, start, err := d.readEntries()
+		if err != nil {
+			return nil, err
+		}
+		ast, err := d.parse(source, start)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range ast.Entries {
+			switch entry := entry.(type) {
+			case *Block:
+				d.pending = append(d.pending, entry)
+			case *Attribute:
+				return nil, participle.Errorf(entry.Pos, "expected a block but got attribute %q", entry.Key)
+			}
+		}
+	}
+	block := d.pending[0]
+	d.pending = d.pending[1:]
+	return block, nil
+}
+
+// DecodeBlock unmarshals the next top-level block into v, which must be a
+// pointer to a struct. It returns io.EOF if there are no more blocks.
+func (d *Decoder) DecodeBlock(v interface{}, options ...MarshalOption) error {
+	block, err := d.Next()
+	if err != nil {
+		return err
+	}
+	return UnmarshalBlock(block, v, options...)
+}
+
+func (d *Decoder) parse(source []byte, start lexer.Position) (*AST, error) {
+	ast, err := parser.ParseBytes(d.config.filename, source)
+	if err != nil {
+		var perr participle.Error
+		if errors.As(err, &perr) {
+			return nil, participle.Errorf(shiftPosition(perr.Position(), start), "%s", perr.Message())
+		}
+		return nil, err
+	}
+	shiftPositions(reflect.ValueOf(ast), start)
+	return d.config.postProccessAST(ast, nil)
+}
+
+var heredocStart = regexp.MustCompile(`^<<-?(\w+)`)
+
+// readEntries reads whole lines up to and including the line on which the
+// next top-level block is closed, or up to the end of the input.
+//
+// It tracks just enough of the lexical structure of HCL, being strings,
+// comments and heredocs, to find the closing brace.
+func (d *Decoder) readEntries() ([]byte, lexer.Position, error) {
+	start := lexer.Position{Filename: d.config.filename, Offset: d.offset, Line: d.line, Column: 1}
+	var (
+		source       []byte
+		depth        int
+		quote        byte
+		blockComment bool
+		heredoc      string
+	)
+	for {
+		line, err := d.r.ReadBytes('\n')
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, start, err
+		}
+		if len(line) == 0 && errors.Is(err, io.EOF) {
+			if len(source) == 0 {
+				return nil, start, io.EOF
+			}
+			return source, start, nil
+		}
+		source = append(source, line...)
+		d.offset += len(line)
+		d.line++
+
+		if heredoc != "" {
+			if string(bytes.TrimSpace(line)) == heredoc {
+				heredoc = ""
+			}
+			continue
+		}
+		closed := false
+	scan:
+		for i := 0; i < len(line); i++ {
+			c := line[i]
+			switch {
+			case blockComment:
+				if bytes.HasPrefix(line[i:], []byte("*/")) {
+					blockComment = false
+					i++
+				}
+			case quote != 0:
+				if c == '\\' {
+					i++
+				} else if c == quote {
+					quote = 0
+				}
+			case c == '"' || c == '\'':
+				quote = c
+			case c == '#' || bytes.HasPrefix(line[i:], []byte("//")):
+				break scan
+			case bytes.HasPrefix(line[i:], []byte("/*")):
+				blockComment = true
+				i++
+			case c == '<':
+				if match := heredocStart.FindSubmatch(line[i:]); match != nil {
+					heredoc = string(match[1])
+					break scan
+				}
+			case c == '{' || c == '[' || c == '(':
+				depth++
+			case c == '}' || c == ']' || c == ')':
+				depth--
+				if depth < 0 {
+					return nil, start, fmt.Errorf("%s: unexpected %q", start, c)
+				}
+				closed = closed || (depth == 0 && c == '}')
+			}
+		}
+		if closed && depth == 0 && quote == 0 && !blockComment && heredoc == "" {
+			return source, start, nil
+		}
+	}
+}
+
+var positionType = reflect.TypeOf(lexer.Position{})
+
+// shiftPositions offsets all positions in a parsed node by the position of
+// the start of its source.
+func shiftPositions(v reflect.Value, start lexer.Position) {
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Interface:
+		if !v.IsNil() {
+			shiftPositions(v.Elem(), start)
+		}
+
+	case reflect.Slice:
+		for i := 0; i < v.Len(); i++ {
+			shiftPositions(v.Index(i), start)
+		}
+
+	case reflect.Struct:
+		if v.Type() == positionType {
+			v.Set(reflect.ValueOf(shiftPosition(v.Interface().(lexer.Position), start)))
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if field.IsExported() && field.Name != "Parent" {
+				shiftPositions(v.Field(i), start)
+			}
+		}
+
+	default:
+	}
+}
+
+func shiftPosition(pos, start lexer.Position) lexer.Position {
+	if pos.Line == 0 {
+		return pos
+	}
+	pos.Offset += start.Offset
+	pos.Line += start.Line - 1
+	return pos
+}
@@ -0,0 +1,78 @@
+package hcl
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestDecoder(t *testing.T) {
+	type record struct {
+		Name   string   `hcl:"name,label"`
+		Value  int      `hcl:"value"`
+		Script string   `hcl:"script,optional"`
+		Tags   []string `hcl:"tags,optional"`
+	}
+	decoder := NewDecoder(strings.NewReader(`
+// The first.
+record "a" {
+  value = 1
+  tags = ["}", "{"] // }
+}
+record "b" { value = 2 } record "c" { value = 3 }
+
+/* { */
+record "d" {
+  value = 4
+  script = <<EOF
+}
+EOF
+}
+// Detached.
+`))
+	records := []record{}
+	for {
+		r := record{}
+		err := decoder.DecodeBlock(&r)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		records = append(records, r)
+	}
+	assert.Equal(t, []record{
+		{Name: "a", Value: 1, Tags: []string{"}", "{"}},
+		{Name: "b", Value: 2},
+		{Name: "c", Value: 3},
+		{Name: "d", Value: 4, Script: "}"},
+	}, records)
+}
+
+func TestDecoderPositions(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("a {\n}\n\n# B.\nb {\n  c = 1\n}\n"), WithFilename("test.hcl"))
+	block, err := decoder.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, "a", block.Name)
+	block, err = decoder.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"B."}, block.Comments)
+	assert.Equal(t, "test.hcl:5:1", block.Pos.String())
+	assert.Equal(t, 12, block.Pos.Offset)
+	assert.Equal(t, "test.hcl:6:3", block.Body[0].Position().String())
+	_, err = decoder.Next()
+	assert.Equal(t, io.EOF, err)
+}
+
+func TestDecoderErrors(t *testing.T) {
+	decoder := NewDecoder(strings.NewReader("a {}\n\nb {\n  c = \n}\n"))
+	_, err := decoder.Next()
+	assert.NoError(t, err)
+	_, err = decoder.Next()
+	assert.EqualError(t, err, `5:1: unexpected token "}" (expected Value)`)
+
+	decoder = NewDecoder(strings.NewReader("b = 1\na {}\n"))
+	_, err = decoder.Next()
+	assert.EqualError(t, err, `1:1: expected a block but got attribute "b"`)
+}
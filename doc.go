@@ -0,0 +1,407 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"html"
+	"io"
+	"math/big"
+	"regexp"
+	"strings"
+)
+
+// DocFormat is the output format of Document.
+type DocFormat int
+
+const (
+	// DocMarkdown renders GitHub flavoured Markdown.
+	DocMarkdown DocFormat = iota
+	// DocHTML renders an HTML fragment.
+	DocHTML
+)
+
+// A DocOption modifies how reference documentation is rendered.
+type DocOption func(options *docState)
+
+type docState struct {
+	format DocFormat
+	title  string
+}
+
+// WithDocFormat sets the output format of the documentation. Defaults to DocMarkdown.
+func WithDocFormat(format DocFormat) DocOption {
+	return func(options *docState) {
+		options.format = format
+	}
+}
+
+// WithDocTitle sets the title of the top-level section. Defaults to "Configuration".
+func WithDocTitle(title string) DocOption {
+	return func(options *docState) {
+		options.title = title
+	}
+}
+
+// Document renders reference documentation for the configuration of a Go value.
+//
+// See DocumentAST for details.
+func Document(v interface{}, options ...DocOption) ([]byte, error) {
+	schema, err := Schema(v)
+	if err != nil {
+		return nil, err
+	}
+	return DocumentAST(schema, options...)
+}
+
+// DocumentAST renders reference documentation from a schema, such as one
+// produced by Schema or BlockSchema.
+//
+// There is a section for the top level and for each block, with an anchor
+// derived from the path of the block. Each section has a table of its
+// attributes, with their type, whether they are required, default, allowed
+// values and help, followed by its labels, nested blocks, and an example
+// containing the defaults of the block.
+func DocumentAST(schema *AST, options ...DocOption) ([]byte, error) {
+	opt := &docState{title: "Configuration"}
+	for _, option := range options {
+		option(opt)
+	}
+	definitions := schemaDefinitions(schema)
+	entries := withoutDefinitions(schema.Entries, definitions)
+	sections := []*docSection{{title: opt.title, anchor: docAnchor(opt.title), entries: entries}}
+	sections = append(sections, docSections("", entries)...)
+	for _, entry := range schema.Entries {
+		block, ok := entry.(*Block)
+		if !ok || !isSchemaDefinition(block) || definitions[block.Labels[0]] != block {
+			continue
+		}
+		title := "type " + block.Labels[0]
+		sections = append(sections, &docSection{title: title, anchor: docAnchor(title), path: block.Labels[0], definition: true, entries: block.Body})
+		sections = append(sections, docSections(block.Labels[0], block.Body)...)
+	}
+	for _, section := range sections {
+		example, err := docExample(section)
+		if err != nil {
+			return nil, err
+		}
+		section.example = example
+	}
+	w := &bytes.Buffer{}
+	switch opt.format {
+	case DocMarkdown:
+		writeMarkdownDoc(w, sections)
+	case DocHTML:
+		writeHTMLDoc(w, sections)
+	default:
+		return nil, fmt.Errorf("unsupported documentation format %d", opt.format)
+	}
+	return w.Bytes(), nil
+}
+
+type docSection struct {
+	title  string
+	anchor string
+	// path of the section, prefixing the titles of nested blocks.
+	path       string
+	block      *Block
+	definition bool
+	entries    []Entry
+	example    string
+}
+
+func (d *docSection) attributes() (attrs []*Attribute) {
+	for _, entry := range d.entries {
+		if attr, ok := entry.(*Attribute); ok {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}
+
+func (d *docSection) blocks() (blocks []*Block) {
+	for _, entry := range d.entries {
+		if block, ok := entry.(*Block); ok {
+			blocks = append(blocks, block)
+		}
+	}
+	return blocks
+}
+
+func (d *docSection) recursive() bool {
+	for _, entry := range d.entries {
+		if _, ok := entry.(*RecursiveEntry); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// docSections returns a section for each block in entries, depth first.
+func docSections(path string, entries []Entry) (sections []*docSection) {
+	for _, entry := range entries {
+		block, ok := entry.(*Block)
+		if !ok {
+			continue
+		}
+		title := docChildPath(path, block)
+		sections = append(sections, &docSection{title: title, anchor: docAnchor(title), path: title, block: block, entries: block.Body})
+		sections = append(sections, docSections(title, block.Body)...)
+	}
+	return sections
+}
+
+func docChildPath(path string, block *Block) string {
+	if path == "" {
+		return block.Name
+	}
+	return path + "." + block.Name
+}
+
+// docTypeAnchor returns the anchor of the section of a type definition.
+func docTypeAnchor(name string) string {
+	return docAnchor("type " + name)
+}
+
+var docAnchorReplacer = regexp.MustCompile(`[^a-z0-9_]+`)
+
+func docAnchor(title string) string {
+	return strings.Trim(docAnchorReplacer.ReplaceAllString(strings.ToLower(title), "-"), "-")
+}
+
+// docExample marshals the defaults of a section. Required attributes without
+// a default are given their first enum value, or the zero value of their type.
+func docExample(section *docSection) (string, error) {
+	entries := docExampleEntries(section.entries)
+	if section.block != nil {
+		entries = []Entry{&Block{Name: section.block.Name, Labels: section.block.Labels, Body: entries}}
+	}
+	if len(entries) == 0 {
+		return "", nil
+	}
+	data, err := MarshalAST(&AST{Entries: entries}, MaxLineWidth(80))
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+func docExampleEntries(entries []Entry) (out []Entry) {
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case *Attribute:
+			value := entry.Default
+			if value == nil {
+				if entry.Optional {
+					continue
+				}
+				if len(entry.Enum) > 0 {
+					value = entry.Enum[0]
+				} else {
+					value = docZeroValue(entry.Value)
+				}
+			}
+			out = append(out, &Attribute{Key: entry.Key, Value: value})
+
+		case *Block:
+			out = append(out, &Block{Name: entry.Name, Labels: entry.Labels, Body: docExampleEntries(entry.Body)})
+		}
+	}
+	return out
+}
+
+func docZeroValue(value Value) Value {
+	switch value := value.(type) {
+	case *Type:
+		switch value.Type {
+		case strType:
+			return &String{}
+		case numType:
+			return &Number{Float: big.NewFloat(0)}
+		case boolType:
+			return &Bool{}
+		case durType:
+			return &String{Str: "0s"}
+		case tsType:
+			return &String{Str: "1970-01-01T00:00:00Z"}
+		case listType:
+			return &List{}
+		case mapType:
+			return &Map{}
+		}
+		if value.Of != nil {
+			return docZeroValue(value.Of)
+		}
+		return &String{}
+	case *List:
+		return &List{}
+	case *Map:
+		return &Map{}
+	}
+	return value
+}
+
+// docRow is the documentation of an attribute.
+type docRow struct {
+	name, typ, required, defaultValue, enum, help string
+}
+
+func docAttribute(attr *Attribute) docRow {
+	comments := append(cloneStrings(attr.Comments), aliasComments(attr.Aliases, attr.Deprecated)...)
+	row := docRow{name: attr.Key, required: "yes", help: strings.Join(comments, " ")}
+	if attr.Value != nil {
+		row.typ = attr.Value.String()
+	}
+	if attr.Optional || attr.Default != nil {
+		row.required = "no"
+	}
+	if attr.Default != nil {
+		row.defaultValue = attr.Default.String()
+	}
+	enum := make([]string, len(attr.Enum))
+	for i, value := range attr.Enum {
+		enum[i] = value.String()
+	}
+	row.enum = strings.Join(enum, ", ")
+	return row
+}
+
+// docBlockComments returns the comments of a block followed by a description
+// of its aliases and deprecation.
+func docBlockComments(block *Block) []string {
+	return append(cloneStrings(block.Comments), aliasComments(block.Aliases, block.Deprecated)...)
+}
+
+func docBlockSummary(block *Block) string {
+	summary := block.Name
+	if block.Repeated {
+		summary += " (repeated)"
+	}
+	return summary
+}
+
+func writeMarkdownDoc(w io.Writer, sections []*docSection) {
+	for i, section := range sections {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		level := "#"
+		if section.block != nil || section.definition {
+			level = "##"
+		}
+		fmt.Fprintf(w, "%s <a id=\"%s\"></a>%s\n", level, section.anchor, section.title)
+		if section.block != nil {
+			if comments := docBlockComments(section.block); len(comments) > 0 {
+				fmt.Fprintf(w, "\n%s\n", strings.Join(comments, "\n"))
+			}
+			if section.block.Repeated {
+				fmt.Fprintf(w, "\nThis block may be repeated.\n")
+			}
+			if len(section.block.Labels) > 0 {
+				fmt.Fprintf(w, "\nLabels: %s\n", markdownCodeList(section.block.Labels))
+			}
+			if section.block.Ref != "" {
+				fmt.Fprintf(w, "\nThis block is of type [%s](#%s).\n", section.block.Ref, docTypeAnchor(section.block.Ref))
+			}
+		}
+		if section.recursive() {
+			fmt.Fprintf(w, "\nThis block is recursive.\n")
+		}
+		if attrs := section.attributes(); len(attrs) > 0 {
+			fmt.Fprintf(w, "\n| Attribute | Type | Required | Default | Values | Description |\n")
+			fmt.Fprintf(w, "|-----------|------|----------|---------|--------|-------------|\n")
+			for _, attr := range attrs {
+				row := docAttribute(attr)
+				fmt.Fprintf(w, "| %s | %s | %s | %s | %s | %s |\n",
+					markdownCode(row.name), markdownCode(row.typ), row.required,
+					markdownCode(row.defaultValue), markdownCode(row.enum), markdownCell(row.help))
+			}
+		}
+		if blocks := section.blocks(); len(blocks) > 0 {
+			links := make([]string, len(blocks))
+			for i, block := range blocks {
+				links[i] = fmt.Sprintf("[%s](#%s)", docBlockSummary(block), docAnchor(docChildPath(section.path, block)))
+			}
+			fmt.Fprintf(w, "\nBlocks: %s\n", strings.Join(links, ", "))
+		}
+		if section.example != "" {
+			fmt.Fprintf(w, "\nExample:\n\n```hcl\n%s```\n", section.example)
+		}
+	}
+}
+
+func markdownCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "`" + markdownCell(s) + "`"
+}
+
+func markdownCodeList(items []string) string {
+	out := make([]string, len(items))
+	for i, item := range items {
+		out[i] = markdownCode(item)
+	}
+	return strings.Join(out, ", ")
+}
+
+func markdownCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
+
+func writeHTMLDoc(w io.Writer, sections []*docSection) {
+	for _, section := range sections {
+		level := 1
+		if section.block != nil || section.definition {
+			level = 2
+		}
+		fmt.Fprintf(w, "<h%d id=\"%s\">%s</h%d>\n", level, section.anchor, html.EscapeString(section.title), level)
+		if section.block != nil {
+			if comments := docBlockComments(section.block); len(comments) > 0 {
+				fmt.Fprintf(w, "<p>%s</p>\n", html.EscapeString(strings.Join(comments, "\n")))
+			}
+			if section.block.Repeated {
+				fmt.Fprintf(w, "<p>This block may be repeated.</p>\n")
+			}
+			if len(section.block.Labels) > 0 {
+				labels := make([]string, len(section.block.Labels))
+				for i, label := range section.block.Labels {
+					labels[i] = "<code>" + html.EscapeString(label) + "</code>"
+				}
+				fmt.Fprintf(w, "<p>Labels: %s</p>\n", strings.Join(labels, ", "))
+			}
+			if section.block.Ref != "" {
+				fmt.Fprintf(w, "<p>This block is of type <a href=\"#%s\">%s</a>.</p>\n", docTypeAnchor(section.block.Ref), html.EscapeString(section.block.Ref))
+			}
+		}
+		if section.recursive() {
+			fmt.Fprintf(w, "<p>This block is recursive.</p>\n")
+		}
+		if attrs := section.attributes(); len(attrs) > 0 {
+			fmt.Fprintf(w, "<table>\n<tr><th>Attribute</th><th>Type</th><th>Required</th><th>Default</th><th>Values</th><th>Description</th></tr>\n")
+			for _, attr := range attrs {
+				row := docAttribute(attr)
+				fmt.Fprintf(w, "<tr><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td><td>%s</td></tr>\n",
+					htmlCode(row.name), htmlCode(row.typ), row.required,
+					htmlCode(row.defaultValue), htmlCode(row.enum), html.EscapeString(row.help))
+			}
+			fmt.Fprintf(w, "</table>\n")
+		}
+		if blocks := section.blocks(); len(blocks) > 0 {
+			links := make([]string, len(blocks))
+			for i, block := range blocks {
+				links[i] = fmt.Sprintf("<a href=\"#%s\">%s</a>", docAnchor(docChildPath(section.path, block)), html.EscapeString(docBlockSummary(block)))
+			}
+			fmt.Fprintf(w, "<p>Blocks: %s</p>\n", strings.Join(links, ", "))
+		}
+		if section.example != "" {
+			fmt.Fprintf(w, "<pre><code class=\"language-hcl\">%s</code></pre>\n", html.EscapeString(section.example))
+		}
+	}
+}
+
+func htmlCode(s string) string {
+	if s == "" {
+		return ""
+	}
+	return "<code>" + html.EscapeString(s) + "</code>"
+}
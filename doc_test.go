@@ -0,0 +1,105 @@
+package hcl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+type docListener struct {
+	Port int  `hcl:"port" help:"Port to listen on."`
+	TLS  bool `hcl:"tls,optional"`
+}
+
+type docService struct {
+	Name      string        `hcl:"name,label"`
+	Replicas  int           `hcl:"replicas" default:"1" help:"Number of replicas."`
+	Listeners []docListener `hcl:"listener,block"`
+}
+
+type docConfig struct {
+	Env      string            `hcl:"env" enum:"dev,prod" help:"Environment | stage."`
+	Tags     map[string]string `hcl:"tags,optional"`
+	Services []docService      `hcl:"service,block" help:"A service."`
+}
+
+func TestDocument(t *testing.T) {
+	data, err := Document(&docConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, "# <a id=\"configuration\"></a>Configuration\n"+`
+| Attribute | Type | Required | Default | Values | Description |
+|-----------|------|----------|---------|--------|-------------|
+| `+"`env` | `string` | yes |  | `\"dev\", \"prod\"` | Environment \\| stage. |\n"+
+		"| `tags` | `{string: string}` | no |  |  |  |\n"+`
+Blocks: [service (repeated)](#service)
+
+Example:
+
+`+"```hcl"+`
+env = "dev"
+
+service name {
+  replicas = 1
+
+  listener {
+    port = 0
+  }
+}
+`+"```"+`
+
+## <a id="service"></a>service
+
+A service.
+
+This block may be repeated.
+
+Labels: `+"`name`"+`
+
+| Attribute | Type | Required | Default | Values | Description |
+|-----------|------|----------|---------|--------|-------------|
+| `+"`replicas` | `number` | no | `1` |  | Number of replicas. |"+`
+
+Blocks: [listener (repeated)](#service-listener)
+
+Example:
+
+`+"```hcl"+`
+service name {
+  replicas = 1
+
+  listener {
+    port = 0
+  }
+}
+`+"```"+`
+
+## <a id="service-listener"></a>service.listener
+
+This block may be repeated.
+
+| Attribute | Type | Required | Default | Values | Description |
+|-----------|------|----------|---------|--------|-------------|
+| `+"`port` | `number` | yes |  |  | Port to listen on. |"+`
+| `+"`tls` | `boolean` | no |  |  |  |"+`
+
+Example:
+
+`+"```hcl"+`
+listener {
+  port = 0
+}
+`+"```"+`
+`, string(data))
+}
+
+func TestDocumentHTML(t *testing.T) {
+	data, err := Document(&docConfig{}, WithDocFormat(DocHTML), WithDocTitle("Server"))
+	assert.NoError(t, err)
+	html := string(data)
+	assert.True(t, strings.HasPrefix(html, `<h1 id="server">Server</h1>`), html)
+	assert.Contains(t, html, `<h2 id="service-listener">service.listener</h2>`)
+	assert.Contains(t, html, `<tr><td><code>replicas</code></td><td><code>number</code></td><td>no</td><td><code>1</code></td><td></td><td>Number of replicas.</td></tr>`)
+	assert.Contains(t, html, `<p>Blocks: <a href="#service-listener">listener (repeated)</a></p>`)
+	assert.Contains(t, html, "<pre><code class=\"language-hcl\">listener {\n  port = 0\n}\n</code></pre>")
+}
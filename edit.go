@@ -0,0 +1,206 @@
+package hcl
+
+import (
+	"fmt"
+
+	"github.com/alecthomas/participle/v2"
+)
+
+// Get returns the single block, attribute or map entry matching a Query path.
+//
+// An error is returned if the path matches no nodes or more than one.
+func Get(node Node, path string) (Node, error) {
+	nodes, err := Query(node, path)
+	if err != nil {
+		return nil, err
+	}
+	switch len(nodes) {
+	case 0:
+		return nil, fmt.Errorf("%q not found", path)
+	case 1:
+		return nodes[0], nil
+	default:
+		return nil, fmt.Errorf("%q is ambiguous, it matches %d nodes", path, len(nodes))
+	}
+}
+
+// Set the value of the attribute or map entry at path, creating it if necessary.
+//
+// node must be an *AST or *Block. Path segments are as for Query, but may not
+// contain wildcards, and labels must match blocks exactly. Intermediate blocks
+// and map entries are created as needed, with labels taken from the path, so
+// this creates the block `service "web"` if it does not exist:
+//
+//	err := hcl.Set(ast, "service[web].port", &hcl.Number{Float: big.NewFloat(8080)})
+//
+// Comments and the order of existing entries are preserved, new attributes are
+// inserted after the last attribute of their body, and new blocks are appended.
+// Parent references are updated.
+func Set(node Node, path string, value Value) error {
+	segments, err := parsePath(path)
+	if err != nil {
+		return err
+	}
+	for _, segment := range segments {
+		if segment.wildcard || segment.recursive {
+			return fmt.Errorf("path %q must not contain wildcards", path)
+		}
+		for _, label := range segment.labels {
+			if label.wildcard {
+				return fmt.Errorf("path %q must not contain wildcards", path)
+			}
+		}
+	}
+	last := segments[len(segments)-1]
+	if len(last.labels) > 0 {
+		return fmt.Errorf("path %q must end with an attribute or map key, not labels", path)
+	}
+	parent := node
+	for _, segment := range segments[:len(segments)-1] {
+		parent, err = setChild(parent, segment)
+		if err != nil {
+			return err
+		}
+	}
+	return setValue(parent, last.name, value)
+}
+
+// Delete the blocks, attributes or map entries matching a Query path.
+//
+// An error is returned if no nodes match.
+func Delete(node Node, path string) error {
+	nodes, err := Query(node, path)
+	if err != nil {
+		return err
+	}
+	if len(nodes) == 0 {
+		return fmt.Errorf("%q not found", path)
+	}
+	for _, node := range nodes {
+		if !node.Detach() {
+			return participle.Errorf(node.Position(), "could not detach %q, are parent references missing?", pathKey(node))
+		}
+	}
+	return nil
+}
+
+// setChild returns the block or map-valued node matching segment, creating it
+// if necessary.
+func setChild(parent Node, segment pathSegment) (Node, error) {
+	for _, child := range pathChildren(parent) {
+		if pathKey(child) != segment.name {
+			continue
+		}
+		switch child := child.(type) {
+		case *Block:
+			if labelsEqual(child.Labels, segment.labels) {
+				return child, nil
+			}
+		case *Attribute, *MapEntry:
+			if len(segment.labels) == 0 && isMapValued(child) {
+				return child, nil
+			}
+			return nil, participle.Errorf(child.Position(), "can't create block %s, %q is not a block or map", segment, segment.name)
+		}
+	}
+	if m := nodeMap(parent); m != nil {
+		if len(segment.labels) > 0 {
+			return nil, participle.Errorf(m.Pos, "can't create block %s in a map", segment)
+		}
+		entry := &MapEntry{Key: &String{Str: segment.name}, Value: &Map{}}
+		m.Entries = append(m.Entries, entry)
+		addParentRefs(m, entry)
+		return entry, nil
+	}
+	entries, ok := nodeEntries(parent)
+	if !ok {
+		return nil, fmt.Errorf("can't create block %s in %T", segment, parent)
+	}
+	block := &Block{Name: segment.name}
+	for _, label := range segment.labels {
+		block.Labels = append(block.Labels, label.label)
+	}
+	*entries = append(*entries, block)
+	addParentRefs(parent, block)
+	return block, nil
+}
+
+func setValue(parent Node, key string, value Value) error {
+	if m := nodeMap(parent); m != nil {
+		for _, entry := range m.Entries {
+			if pathKey(entry) == key {
+				entry.Value = value
+				addParentRefs(entry, value)
+				return nil
+			}
+		}
+		entry := &MapEntry{Key: &String{Str: key}, Value: value}
+		m.Entries = append(m.Entries, entry)
+		addParentRefs(m, entry)
+		return nil
+	}
+	entries, ok := nodeEntries(parent)
+	if !ok {
+		return fmt.Errorf("can't set %q in %T", key, parent)
+	}
+	insert := 0
+	for i, entry := range *entries {
+		switch entry := entry.(type) {
+		case *Attribute:
+			if entry.Key == key {
+				entry.Value = value
+				addParentRefs(entry, value)
+				return nil
+			}
+			insert = i + 1
+		case *Block:
+			if entry.Name == key {
+				return participle.Errorf(entry.Pos, "can't set attribute %q, a block with the same name exists", key)
+			}
+		}
+	}
+	attr := &Attribute{Key: key, Value: value}
+	*entries = append((*entries)[:insert], append(Entries{attr}, (*entries)[insert:]...)...)
+	addParentRefs(parent, attr)
+	return nil
+}
+
+func labelsEqual(labels []string, predicates []labelPredicate) bool {
+	if len(labels) != len(predicates) {
+		return false
+	}
+	for i, predicate := range predicates {
+		if labels[i] != predicate.label {
+			return false
+		}
+	}
+	return true
+}
+
+func isMapValued(node Node) bool {
+	return nodeMap(node) != nil
+}
+
+// nodeMap returns the map value of an attribute or map entry, if any.
+func nodeMap(node Node) *Map {
+	var value Value
+	switch node := node.(type) {
+	case *Attribute:
+		value = node.Value
+	case *MapEntry:
+		value = node.Value
+	}
+	m, _ := value.(*Map)
+	return m
+}
+
+// nodeEntries returns the body of an AST or block.
+func nodeEntries(node Node) (*Entries, bool) {
+	switch node := node.(type) {
+	case *AST:
+		return &node.Entries, true
+	case *Block:
+		return &node.Body, true
+	}
+	return nil, false
+}
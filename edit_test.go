@@ -0,0 +1,108 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestGet(t *testing.T) {
+	ast, err := ParseString(queryHCL)
+	assert.NoError(t, err)
+
+	node, err := Get(ast, "service[db].port")
+	assert.NoError(t, err)
+	assert.Equal(t, "port = 5432", node.(*Attribute).String())
+
+	_, err = Get(ast, "service[web].listener.port")
+	assert.EqualError(t, err, `"service[web].listener.port" is ambiguous, it matches 2 nodes`)
+
+	_, err = Get(ast, "service[api]")
+	assert.EqualError(t, err, `"service[api]" not found`)
+}
+
+func TestSet(t *testing.T) {
+	ast, err := ParseString(`
+		// The version.
+		version = "1.0.0"
+
+		service web {
+			labels = {
+				"env": "prod",
+			}
+
+			listener {}
+		}
+	`)
+	assert.NoError(t, err)
+
+	assert.NoError(t, Set(ast, "version", str("1.1.0")))
+	assert.NoError(t, Set(ast, "service[web].port", num(80)))
+	assert.NoError(t, Set(ast, "service[web].labels.env", str("dev")))
+	assert.NoError(t, Set(ast, "service[web].labels.team.name", str("ops")))
+	node, err := Get(ast, "service[web].labels.team.name")
+	assert.NoError(t, err)
+	assert.Equal(t, `"ops"`, node.(*MapEntry).Value.String())
+	assert.NoError(t, Delete(ast, "service[web].labels.team"))
+	assert.NoError(t, Set(ast, "service[db].port", num(5432)))
+	assert.NoError(t, Set(ast, "name", str("app")))
+
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `// The version.
+version = "1.1.0"
+name = "app"
+
+service web {
+  labels = {
+    "env": "dev",
+  }
+  port = 80
+
+  listener {}
+}
+
+service db {
+  port = 5432
+}
+`, string(data))
+
+	// Parent references are maintained.
+	node, err = Get(ast, "service[db].port")
+	assert.NoError(t, err)
+	assert.True(t, node.Detach())
+}
+
+func TestSetErrors(t *testing.T) {
+	ast, err := ParseString(`
+		attr = 1
+		block {}
+	`)
+	assert.NoError(t, err)
+	assert.EqualError(t, Set(ast, "block", num(1)), `3:3: can't set attribute "block", a block with the same name exists`)
+	assert.EqualError(t, Set(ast, "attr.key", num(1)), `2:3: can't create block attr, "attr" is not a block or map`)
+	assert.EqualError(t, Set(ast, "*.key", num(1)), `path "*.key" must not contain wildcards`)
+	assert.EqualError(t, Set(ast, "block[a]", num(1)), `path "block[a]" must end with an attribute or map key, not labels`)
+}
+
+func TestDelete(t *testing.T) {
+	ast, err := ParseString(queryHCL)
+	assert.NoError(t, err)
+	assert.NoError(t, Delete(ast, "service[web].listener"))
+	assert.NoError(t, Delete(ast, `service[web].labels."my key"`))
+	assert.NoError(t, Delete(ast, "route"))
+	assert.EqualError(t, Delete(ast, "route"), `"route" not found`)
+
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `service web {
+  labels = {
+    "env": "prod",
+  }
+}
+
+service db {
+  port = 5432
+}
+`, string(data))
+}
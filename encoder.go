@@ -0,0 +1,86 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"reflect"
+)
+
+// An Encoder writes top-level blocks and attributes to an output stream one at
+// a time.
+//
+// Each entry is written as soon as it is encoded, formatted and separated from
+// the previous entry as Marshal would.
+type Encoder struct {
+	w    io.Writer
+	opt  *marshalState
+	prev Entry
+}
+
+// NewEncoder creates an Encoder writing to w.
+func NewEncoder(w io.Writer, options ...MarshalOption) *Encoder {
+	return &Encoder{w: w, opt: newMarshalState(options...)}
+}
+
+// EncodeBlock writes a block with the given name, whose body is marshalled
+// from v, which must be a struct or pointer to a struct.
+//
+// The labels of the block are labels followed by the values of any label
+// fields in v.
+func (e *Encoder) EncodeBlock(name string, labels []string, v interface{}) error {
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Ptr {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fmt.Errorf("expected a struct or pointer to a struct, not %T", v)
+	}
+	body, structLabels, err := structToEntries(rv, e.opt)
+	if err != nil {
+		return err
+	}
+	block := &Block{
+		Name:   name,
+		Labels: append(append([]string{}, labels...), structLabels...),
+		Body:   body,
+	}
+	return e.encode(block)
+}
+
+// EncodeAttr writes an attribute with the given key and value marshalled from v.
+func (e *Encoder) EncodeAttr(key string, v interface{}) error {
+	if v == nil {
+		return fmt.Errorf("can't encode nil value for attribute %q", key)
+	}
+	value, err := valueToValue(reflect.ValueOf(v), e.opt)
+	if err != nil {
+		return err
+	}
+	return e.encode(&Attribute{Key: key, Value: value})
+}
+
+// EncodeComment writes a comment that is not attached to any entry.
+func (e *Encoder) EncodeComment(comment string) error {
+	return e.encode(&Comment{Comments: []string{comment}})
+}
+
+// EncodeEntry writes an already constructed block, attribute or comment.
+func (e *Encoder) EncodeEntry(entry Entry) error {
+	return e.encode(entry)
+}
+
+func (e *Encoder) encode(entry Entry) error {
+	w := &bytes.Buffer{}
+	if e.prev != nil && e.opt.blankLine(e.prev, entry) {
+		fmt.Fprintln(w)
+	}
+	if err := marshalNode(w, "", entry, e.opt); err != nil {
+		return err
+	}
+	if _, err := e.w.Write(w.Bytes()); err != nil {
+		return err
+	}
+	e.prev = entry
+	return nil
+}
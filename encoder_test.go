@@ -0,0 +1,65 @@
+package hcl
+
+import (
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestEncoder(t *testing.T) {
+	type record struct {
+		Name  string `hcl:"name,label"`
+		Value int    `hcl:"value" help:"The value."`
+	}
+	w := &strings.Builder{}
+	encoder := NewEncoder(w, WithSchemaComments(true))
+	assert.NoError(t, encoder.EncodeComment("Generated."))
+	assert.NoError(t, encoder.EncodeAttr("version", 2))
+	assert.NoError(t, encoder.EncodeAttr("tags", []string{"a", "b"}))
+	assert.NoError(t, encoder.EncodeBlock("record", nil, &record{Name: "a", Value: 1}))
+	assert.NoError(t, encoder.EncodeBlock("record", []string{"x"}, record{Name: "b", Value: 2}))
+	assert.Equal(t, `// Generated.
+
+version = 2
+tags = ["a", "b"]
+
+record a {
+  // The value.
+  value = 1
+}
+
+record x b {
+  // The value.
+  value = 2
+}
+`, w.String())
+
+	err := encoder.EncodeBlock("record", nil, 1)
+	assert.EqualError(t, err, "expected a struct or pointer to a struct, not int")
+}
+
+func TestEncoderDecoderRoundTrip(t *testing.T) {
+	type record struct {
+		Name  string `hcl:"name,label"`
+		Value int    `hcl:"value"`
+	}
+	w := &strings.Builder{}
+	encoder := NewEncoder(w)
+	for i, name := range []string{"a", "b", "c"} {
+		assert.NoError(t, encoder.EncodeBlock("record", nil, &record{Name: name, Value: i}))
+	}
+	decoder := NewDecoder(strings.NewReader(w.String()))
+	records := []record{}
+	for {
+		r := record{}
+		err := decoder.DecodeBlock(&r)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		records = append(records, r)
+	}
+	assert.Equal(t, []record{{"a", 0}, {"b", 1}, {"c", 2}}, records)
+}
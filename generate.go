@@ -0,0 +1,455 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"go/format"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// A GenerateOption modifies how Go source is generated.
+type GenerateOption func(options *generateState)
+
+type generateState struct {
+	pkg  string
+	root string
+}
+
+// GoPackage sets the package name of generated Go source. Defaults to "config".
+func GoPackage(name string) GenerateOption {
+	return func(options *generateState) {
+		options.pkg = name
+	}
+}
+
+// GoRootType sets the name of the top-level generated struct. Defaults to "Config".
+func GoRootType(name string) GenerateOption {
+	return func(options *generateState) {
+		options.root = name
+	}
+}
+
+// GenerateGo generates Go structs that unmarshal documents described by a
+// schema, such as one produced by Schema, or inferred from a sample document.
+//
+// This is the reverse of Schema. Attributes and blocks are tagged with their
+// names, help:"" from comments, and default:"" and enum:"" from their schema
+// constraints. Optional attributes without a default are pointers, blocks are
+// pointers to structs, and repeated blocks are slices of structs.
+//
+// Attributes with literal values, as in a sample document, are typed by
+// example, with integral numbers as int and other numbers as float64. Blocks
+// in a sample that occur more than once are repeated, and attributes missing
+// from some occurrences are optional. Schema numbers are float64.
+//
+// Blocks referring to type definitions are generated as structs named after
+// the definition. Recursive blocks in schemas marked with a "(recursive)"
+// comment refer to the nearest enclosing block of the same name, or the root
+// struct.
+func GenerateGo(schema *AST, options ...GenerateOption) ([]byte, error) {
+	opt := &generateState{pkg: "config", root: "Config"}
+	for _, option := range options {
+		option(opt)
+	}
+	definitions := schemaDefinitions(schema)
+	g := &goGenerator{
+		schema:      isSchemaAST(schema),
+		taken:       map[string]bool{opt.root: true},
+		definitions: definitions,
+		refs:        map[string]string{},
+	}
+	g.generate(opt.root, []*Block{{Body: withoutDefinitions(schema.Entries, definitions)}}, nil)
+	w := &bytes.Buffer{}
+	fmt.Fprintf(w, "// Code generated by hclgen. DO NOT EDIT.\n\npackage %s\n", opt.pkg)
+	if g.imports("time.") {
+		fmt.Fprintf(w, "\nimport \"time\"\n")
+	}
+	for _, s := range g.structs {
+		fmt.Fprintf(w, "\ntype %s struct {\n", s.name)
+		for _, f := range s.fields {
+			fmt.Fprintf(w, "%s %s `%s`\n", f.name, f.typ, f.tag)
+		}
+		fmt.Fprintf(w, "}\n")
+	}
+	return format.Source(w.Bytes())
+}
+
+type goGenerator struct {
+	schema      bool
+	structs     []*goStruct
+	taken       map[string]bool
+	definitions map[string]*Block
+	// refs maps the names of type definitions to their struct types.
+	refs map[string]string
+	// ancestors are the enclosing blocks of the struct being generated.
+	ancestors []goAncestor
+}
+
+// imports returns true if any field type references the package prefix.
+func (g *goGenerator) imports(prefix string) bool {
+	for _, s := range g.structs {
+		for _, f := range s.fields {
+			if strings.Contains(f.typ, prefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+type goAncestor struct {
+	block    string
+	typeName string
+}
+
+type goStruct struct {
+	name   string
+	fields []goField
+}
+
+type goField struct {
+	name, typ, tag string
+}
+
+// generate a struct named name from all occurrences of a block.
+func (g *goGenerator) generate(name string, occurrences []*Block, labels []string) {
+	s := &goStruct{name: name}
+	g.structs = append(g.structs, s)
+	names := map[string]bool{}
+	addField := func(key, typ, tag string) {
+		fieldName := goName(key)
+		for i := 2; names[fieldName]; i++ {
+			fieldName = goName(key) + strconv.Itoa(i)
+		}
+		names[fieldName] = true
+		s.fields = append(s.fields, goField{fieldName, typ, tag})
+	}
+	for _, label := range labels {
+		addField(label, "string", fmt.Sprintf("hcl:%q", label+",label"))
+	}
+
+	// Group the entries of all occurrences by key, preserving the order in
+	// which keys first appear.
+	var keys []string
+	attrs := map[string][]*Attribute{}
+	blocks := map[string][]*Block{}
+	repeated := map[string]bool{}
+	present := map[string]int{}
+	for _, occurrence := range occurrences {
+		counts := map[string]int{}
+		for _, entry := range occurrence.Body {
+			switch entry := entry.(type) {
+			case *Attribute:
+				if attrs[entry.Key] == nil && blocks[entry.Key] == nil {
+					keys = append(keys, entry.Key)
+				}
+				attrs[entry.Key] = append(attrs[entry.Key], entry)
+				present[entry.Key]++
+			case *Block:
+				if attrs[entry.Name] == nil && blocks[entry.Name] == nil {
+					keys = append(keys, entry.Name)
+				}
+				blocks[entry.Name] = append(blocks[entry.Name], entry)
+				counts[entry.Name]++
+				if entry.Repeated || counts[entry.Name] > 1 {
+					repeated[entry.Name] = true
+				}
+			}
+		}
+	}
+
+	for _, key := range keys {
+		if values := attrs[key]; values != nil {
+			attr := values[0]
+			optional := attr.Optional || attr.Default != nil || present[key] < len(occurrences)
+			addField(key, g.attributeType(attr, optional, values), g.attributeTag(attr, optional))
+			continue
+		}
+		block := blocks[key][0]
+		typeName := g.blockType(block, blocks[key])
+		typ := "*" + typeName
+		if repeated[key] {
+			typ = "[]" + typeName
+		}
+		tag := fmt.Sprintf("hcl:%q", key+",block")
+		if help := strings.Join(block.Comments, " "); help != "" {
+			tag += fmt.Sprintf(" help:%q", help)
+		}
+		tag += aliasTags(block.Aliases, block.Deprecated)
+		addField(key, typ, tag)
+	}
+}
+
+// blockType returns the name of the struct type of a block, generating it if
+// necessary.
+func (g *goGenerator) blockType(block *Block, occurrences []*Block) string {
+	if definition, ok := g.definitions[block.Ref]; ok {
+		if name, ok := g.refs[block.Ref]; ok {
+			return name
+		}
+		name := g.typeName(goName(block.Ref))
+		g.refs[block.Ref] = name
+		g.generate(name, []*Block{definition}, block.Labels)
+		return name
+	}
+	if isRecursiveBlock(block) {
+		for i := len(g.ancestors) - 1; i >= 0; i-- {
+			if g.ancestors[i].block == block.Name {
+				return g.ancestors[i].typeName
+			}
+		}
+		return g.structs[0].name
+	}
+	name := goName(block.Name)
+	if g.taken[name] && len(g.ancestors) > 0 {
+		name = g.ancestors[len(g.ancestors)-1].typeName + name
+	}
+	name = g.typeName(name)
+	labels := block.Labels
+	if !g.schema {
+		labels = sampleLabels(occurrences)
+	}
+	g.ancestors = append(g.ancestors, goAncestor{block.Name, name})
+	g.generate(name, occurrences, labels)
+	g.ancestors = g.ancestors[:len(g.ancestors)-1]
+	return name
+}
+
+// typeName reserves a unique struct type name based on name.
+func (g *goGenerator) typeName(name string) string {
+	for base, i := name, 2; g.taken[name]; i++ {
+		name = base + strconv.Itoa(i)
+	}
+	g.taken[name] = true
+	return name
+}
+
+func (g *goGenerator) attributeType(attr *Attribute, optional bool, occurrences []*Attribute) string {
+	values := make([]Value, len(occurrences))
+	for i, occurrence := range occurrences {
+		values[i] = occurrence.Value
+	}
+	typ := goType(values)
+	if optional && attr.Default == nil && !strings.HasPrefix(typ, "[]") && !strings.HasPrefix(typ, "map[") && typ != "interface{}" {
+		typ = "*" + typ
+	}
+	return typ
+}
+
+func (g *goGenerator) attributeTag(attr *Attribute, optional bool) string {
+	name := attr.Key
+	if optional && attr.Default == nil {
+		name += ",optional"
+	}
+	tag := fmt.Sprintf("hcl:%q", name)
+	if help := strings.Join(attr.Comments, " "); help != "" {
+		tag += fmt.Sprintf(" help:%q", help)
+	}
+	if attr.Default != nil {
+		tag += fmt.Sprintf(" default:%q", tagValue(attr.Default))
+	}
+	if len(attr.Enum) > 0 {
+		enum := make([]string, len(attr.Enum))
+		for i, value := range attr.Enum {
+			enum[i] = tagValue(value)
+		}
+		tag += fmt.Sprintf(" enum:%q", strings.Join(enum, ","))
+	}
+	return tag + aliasTags(attr.Aliases, attr.Deprecated)
+}
+
+// aliasTags returns the aliases:"" and deprecated:"" tags of a schema entry.
+func aliasTags(aliases []string, deprecated string) (tag string) {
+	if len(aliases) > 0 {
+		tag += fmt.Sprintf(" aliases:%q", strings.Join(aliases, ","))
+	}
+	if deprecated != "" {
+		tag += fmt.Sprintf(" deprecated:%q", deprecated)
+	}
+	return tag
+}
+
+// goType returns the Go type of a schema type, or the common type of sample values.
+func goType(values []Value) string {
+	var typ string
+	for _, value := range values {
+		valueType := goValueType(value)
+		switch {
+		case typ == "" || typ == valueType:
+			typ = valueType
+		case typ == "int" && valueType == "float64" || typ == "float64" && valueType == "int":
+			typ = "float64"
+		default:
+			return "interface{}"
+		}
+	}
+	if typ == "" {
+		return "interface{}"
+	}
+	return typ
+}
+
+func goValueType(value Value) string {
+	switch value := value.(type) {
+	case *Type:
+		switch value.Type {
+		case strType:
+			return "string"
+		case numType:
+			return "float64"
+		case boolType:
+			return "bool"
+		case durType:
+			return "time.Duration"
+		case tsType:
+			return "time.Time"
+		case listType:
+			return "[]" + goValueType(value.Of)
+		case mapType:
+			return "map[string]" + goValueType(value.Of)
+		}
+		if value.Of != nil {
+			return goValueType(value.Of)
+		}
+	case *String, *Heredoc:
+		return "string"
+	case *Number:
+		if value.Float.IsInt() {
+			return "int"
+		}
+		return "float64"
+	case *Bool:
+		return "bool"
+	case *List:
+		element := goType(value.List)
+		if len(value.List) == 0 {
+			element = "string"
+		}
+		return "[]" + element
+	case *Map:
+		values := make([]Value, len(value.Entries))
+		for i, entry := range value.Entries {
+			values[i] = entry.Value
+		}
+		element := goType(values)
+		if len(value.Entries) == 0 {
+			element = "string"
+		}
+		key := "string"
+		if len(value.Entries) > 0 {
+			if t, ok := value.Entries[0].Key.(*Type); ok && t.Type == numType {
+				key = "int"
+			}
+		}
+		return "map[" + key + "]" + element
+	}
+	return "interface{}"
+}
+
+// tagValue formats a value as accepted by default:"" and enum:"" tags.
+func tagValue(value Value) string {
+	switch value := value.(type) {
+	case *String:
+		return value.Str
+	case *Heredoc:
+		return value.GetHeredoc()
+	case *List:
+		elements := make([]string, len(value.List))
+		for i, element := range value.List {
+			elements[i] = tagValue(element)
+		}
+		return strings.Join(elements, ",")
+	case *Map:
+		entries := make([]string, len(value.Entries))
+		for i, entry := range value.Entries {
+			entries[i] = tagValue(entry.Key) + "=" + tagValue(entry.Value)
+		}
+		return strings.Join(entries, ";")
+	default:
+		return value.String()
+	}
+}
+
+// sampleLabels names the labels of blocks in a sample document.
+func sampleLabels(occurrences []*Block) []string {
+	count := 0
+	for _, block := range occurrences {
+		if len(block.Labels) > count {
+			count = len(block.Labels)
+		}
+	}
+	if count == 1 {
+		return []string{"name"}
+	}
+	labels := make([]string, count)
+	for i := range labels {
+		labels[i] = "label" + strconv.Itoa(i)
+	}
+	return labels
+}
+
+func isRecursiveBlock(block *Block) bool {
+	for _, entry := range block.Body {
+		if _, ok := entry.(*RecursiveEntry); ok {
+			return true
+		}
+	}
+	for _, comment := range block.TrailingComments {
+		if comment == "(recursive)" {
+			return true
+		}
+	}
+	return false
+}
+
+// isSchemaAST returns true if the AST is a schema rather than a sample document.
+func isSchemaAST(ast *AST) bool {
+	if ast.Schema {
+		return true
+	}
+	schema := false
+	_ = Visit(ast, func(node Node, next func() error) error {
+		switch node := node.(type) {
+		case *Type:
+			schema = true
+		case *Block:
+			if node.Repeated {
+				schema = true
+			}
+		case *Attribute:
+			if node.Optional || node.Default != nil || len(node.Enum) > 0 {
+				schema = true
+			}
+		}
+		return next()
+	})
+	return schema
+}
+
+var goInitialisms = map[string]bool{
+	"API": true, "CPU": true, "DNS": true, "HTTP": true, "HTTPS": true, "ID": true, "IP": true,
+	"JSON": true, "SQL": true, "SSH": true, "TCP": true, "TLS": true, "TTL": true, "UDP": true,
+	"URI": true, "URL": true, "UUID": true,
+}
+
+// goName converts an HCL identifier to an exported Go identifier.
+func goName(name string) string {
+	words := strings.FieldsFunc(name, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	w := &strings.Builder{}
+	for _, word := range words {
+		if upper := strings.ToUpper(word); goInitialisms[upper] {
+			w.WriteString(upper)
+			continue
+		}
+		w.WriteString(strings.ToUpper(word[:1]) + word[1:])
+	}
+	out := w.String()
+	if out == "" || unicode.IsDigit(rune(out[0])) {
+		out = "X" + out
+	}
+	return out
+}
@@ -0,0 +1,117 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestGenerateGoFromSchema(t *testing.T) {
+	schema, err := Schema(&testSchema{})
+	assert.NoError(t, err)
+	data, err := GenerateGo(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, "// Code generated by hclgen. DO NOT EDIT.\n\npackage config\n"+`
+type Config struct {
+	Str        string             `+"`hcl:\"str\" help:\"A string field.\"`"+`
+	Num        *float64           `+"`hcl:\"num,optional\"`"+`
+	Bool       bool               `+"`hcl:\"bool\"`"+`
+	List       []string           `+"`hcl:\"list\"`"+`
+	Map        map[string]float64 `+"`hcl:\"map\" help:\"A map.\"`"+`
+	Map2       map[int]string     `+"`hcl:\"map2\" help:\"Another map.\"`"+`
+	Block      *Block             `+"`hcl:\"block,block\" help:\"A block.\"`"+`
+	BlockSlice []BlockSlice       `+"`hcl:\"block_slice,block\" help:\"Repeated blocks.\"`"+`
+	DefaultStr string             `+"`hcl:\"default_str\" default:\"def\"`"+`
+	EnumStr    string             `+"`hcl:\"enum_str\" enum:\"a,b,c\"`"+`
+}
+
+type Block struct {
+	Name string `+"`hcl:\"name,label\"`"+`
+	Attr string `+"`hcl:\"attr\"`"+`
+}
+
+type BlockSlice struct {
+	Label0 string `+"`hcl:\"label0,label\"`"+`
+	Label1 string `+"`hcl:\"label1,label\"`"+`
+	Attr   string `+"`hcl:\"attr\"`"+`
+}
+`, string(data))
+}
+
+func TestGenerateGoRecursive(t *testing.T) {
+	data, err := MarshalAST(MustSchema(&RecursiveSchema{}))
+	assert.NoError(t, err)
+	schema, err := ParseBytes(data, WithSchema(true))
+	assert.NoError(t, err)
+	data, err = GenerateGo(schema, GoPackage("users"), GoRootType("User"))
+	assert.NoError(t, err)
+	assert.Equal(t, "// Code generated by hclgen. DO NOT EDIT.\n\npackage users\n"+`
+type User struct {
+	Name      string           `+"`hcl:\"name\" help:\"Name of user.\"`"+`
+	Age       *float64         `+"`hcl:\"age,optional\" help:\"Age of user.\"`"+`
+	Recursive *RecursiveSchema `+"`hcl:\"recursive,block\"`"+`
+}
+
+type RecursiveSchema struct {
+	Name      string           `+"`hcl:\"name\" help:\"Name of user.\"`"+`
+	Age       *float64         `+"`hcl:\"age,optional\" help:\"Age of user.\"`"+`
+	Recursive *RecursiveSchema `+"`hcl:\"recursive,block\"`"+`
+}
+`, string(data))
+
+	// Schemas marking recursion with a comment refer to the enclosing block.
+	schema, err = ParseString("name = string\n\nrecursive {\n  // (recursive)\n}\n")
+	assert.NoError(t, err)
+	data, err = GenerateGo(schema, GoRootType("User"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), "Recursive *User  `hcl:\"recursive,block\"`")
+}
+
+func TestGenerateGoFromSample(t *testing.T) {
+	sample, err := ParseString(`
+name = "x"
+port = 80
+ratio = 0.5
+tags = ["a"]
+labels = {a: 1}
+
+service "web" {
+  replicas = 1
+  tls_enabled = true
+}
+
+service "db" {
+  replicas = 2
+  api_url = "u"
+}
+
+server {
+  host = "h"
+}
+`)
+	assert.NoError(t, err)
+	data, err := GenerateGo(sample)
+	assert.NoError(t, err)
+	assert.Equal(t, "// Code generated by hclgen. DO NOT EDIT.\n\npackage config\n"+`
+type Config struct {
+	Name    string         `+"`hcl:\"name\"`"+`
+	Port    int            `+"`hcl:\"port\"`"+`
+	Ratio   float64        `+"`hcl:\"ratio\"`"+`
+	Tags    []string       `+"`hcl:\"tags\"`"+`
+	Labels  map[string]int `+"`hcl:\"labels\"`"+`
+	Service []Service      `+"`hcl:\"service,block\"`"+`
+	Server  *Server        `+"`hcl:\"server,block\"`"+`
+}
+
+type Service struct {
+	Name       string  `+"`hcl:\"name,label\"`"+`
+	Replicas   int     `+"`hcl:\"replicas\"`"+`
+	TLSEnabled *bool   `+"`hcl:\"tls_enabled,optional\"`"+`
+	APIURL     *string `+"`hcl:\"api_url,optional\"`"+`
+}
+
+type Server struct {
+	Host string `+"`hcl:\"host\"`"+`
+}
+`, string(data))
+}
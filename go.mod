@@ -3,9 +3,11 @@ module github.com/alecthomas/hcl/v2
 go 1.18
 
 require (
+	github.com/BurntSushi/toml v1.4.0
 	github.com/alecthomas/assert/v2 v2.11.0
 	github.com/alecthomas/participle/v2 v2.1.4
 	github.com/alecthomas/repr v0.4.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require github.com/hexops/gotextdiff v1.0.3 // indirect
@@ -0,0 +1,141 @@
+package hcl
+
+import (
+	"io/fs"
+	pathpkg "path"
+	"path/filepath"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// WithIncluder enables "@include" directives, which are replaced during
+// parsing by the entries of the files they include from fsys:
+//
+//	@include "services/*.hcl"
+//
+// Relative paths are resolved against the directory of the including file, as
+// set by WithFilename, and must then be valid fs.FS paths, eg. for os.DirFS.
+// Globs are expanded with fs.Glob, and must match at least one file. The
+// positions of included entries report the path of their file. Include cycles
+// are an error.
+//
+// Directives may appear anywhere an attribute or block can, and may not be
+// combined with WithLossless.
+func WithIncluder(fsys fs.FS) ParseOption {
+	return func(config *parseConfig) {
+		config.includer = fsys
+	}
+}
+
+// includeDirective is an "@include" directive. It is only present in the AST
+// during parsing.
+type includeDirective struct {
+	Pos lexer.Position
+
+	Path string `parser:"'@' 'include' @String"`
+}
+
+var _ Entry = &includeDirective{}
+
+func (i *includeDirective) Detach() bool                { return false }
+func (i *includeDirective) Clone() Entry                { clone := *i; return &clone }
+func (i *includeDirective) EntryKey() string            { return "" }
+func (i *includeDirective) Position() Position          { return i.Pos }
+func (i *includeDirective) children() (children []Node) { return nil }
+
+// resolveIncludes replaces include directives in the AST with the entries of
+// the files they include.
+func (config *parseConfig) resolveIncludes(hcl *AST) (resolved bool, err error) {
+	hcl.Entries, resolved, err = config.resolveIncludesInEntries(hcl.Entries)
+	if err != nil {
+		return false, err
+	}
+	err = visitBlocks(hcl, func(block *Block) error {
+		var blockResolved bool
+		block.Body, blockResolved, err = config.resolveIncludesInEntries(block.Body)
+		resolved = resolved || blockResolved
+		return err
+	})
+	if err != nil {
+		return false, err
+	}
+	if resolved {
+		addParentRefs(nil, hcl)
+	}
+	return resolved, nil
+}
+
+func (config *parseConfig) resolveIncludesInEntries(entries Entries) (out Entries, resolved bool, err error) {
+	for _, entry := range entries {
+		directive, ok := entry.(*includeDirective)
+		if !ok {
+			out = append(out, entry)
+			continue
+		}
+		included, err := config.include(directive)
+		if err != nil {
+			return nil, false, err
+		}
+		out = append(out, included...)
+		resolved = true
+	}
+	return out, resolved, nil
+}
+
+// include parses the files included by a directive and returns their entries.
+func (config *parseConfig) include(directive *includeDirective) ([]Entry, error) {
+	if config.includer == nil {
+		return nil, participle.Errorf(directive.Pos, "@include requires WithIncluder")
+	}
+	include := directive.Path
+	if !pathpkg.IsAbs(include) && config.filename != "" {
+		include = pathpkg.Join(pathpkg.Dir(filepath.ToSlash(config.filename)), include)
+	}
+	if !fs.ValidPath(include) {
+		return nil, participle.Errorf(directive.Pos, "invalid include %q, paths must be within the includer's file system", directive.Path)
+	}
+	paths := []string{include}
+	if strings.ContainsAny(include, "*?[") {
+		var err error
+		paths, err = fs.Glob(config.includer, include)
+		if err != nil {
+			return nil, participle.Wrapf(directive.Pos, err, "invalid include %q", directive.Path)
+		}
+		if len(paths) == 0 {
+			return nil, participle.Errorf(directive.Pos, "include %q matched no files", directive.Path)
+		}
+	}
+	parents := config.includeStack
+	if len(parents) == 0 && config.filename != "" {
+		parents = []string{pathpkg.Clean(filepath.ToSlash(config.filename))}
+	}
+	var entries []Entry
+	for _, name := range paths {
+		stack := append(append([]string{}, parents...), name)
+		for _, parent := range parents {
+			if parent == name {
+				return nil, participle.Errorf(directive.Pos, "include cycle %s", strings.Join(stack, " -> "))
+			}
+		}
+		data, err := fs.ReadFile(config.includer, name)
+		if err != nil {
+			return nil, participle.Wrapf(directive.Pos, err, "failed to include %q", name)
+		}
+		child := *config
+		child.filename = name
+		child.includeStack = stack
+		child.lossless = false
+		hcl, err := parser.ParseBytes(name, data)
+		if err != nil {
+			return nil, err
+		}
+		hcl, err = child.postProccessAST(hcl, data)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, hcl.Entries...)
+	}
+	return entries, nil
+}
@@ -0,0 +1,89 @@
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestInclude(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"main.hcl": `
+version = 1
+@include "services/*.hcl"
+
+defaults {
+  @include "defaults.hcl"
+}
+`,
+		"defaults.hcl":        "timeout = 10\n",
+		"services/api.hcl":    "service api {\n  port = 8080\n}\n",
+		"services/web.hcl":    "service web {\n  port = 80\n}\n",
+		"services/README.txt": "not included",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(path), 0700))
+		assert.NoError(t, os.WriteFile(path, []byte(content), 0600))
+	}
+	data, err := os.ReadFile(filepath.Join(dir, "main.hcl"))
+	assert.NoError(t, err)
+	ast, err := ParseBytes(data, WithFilename("main.hcl"), WithIncluder(os.DirFS(dir)))
+	assert.NoError(t, err)
+	out, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `version = 1
+
+service api {
+  port = 8080
+}
+
+service web {
+  port = 80
+}
+
+defaults {
+  timeout = 10
+}
+`, string(out))
+	web := ast.Entries[2].(*Block)
+	assert.Equal(t, "services/web.hcl:1:1", web.Pos.String())
+	assert.Equal[Node](t, ast, web.Parent)
+
+	_, err = ParseBytes(data, WithFilename("main.hcl"))
+	assert.EqualError(t, err, "main.hcl:3:1: @include requires WithIncluder")
+}
+
+func TestIncludeFS(t *testing.T) {
+	fsys := fstest.MapFS{
+		"conf/services/api.hcl": {Data: []byte("service api {}\n")},
+		"conf/services/web.hcl": {Data: []byte("service web {}\n")},
+	}
+	ast, err := ParseString(`@include "services/*.hcl"`, WithFilename("conf/main.hcl"), WithIncluder(fsys))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, len(ast.Entries))
+	assert.Equal(t, "conf/services/web.hcl:1:1", ast.Entries[1].Position().String())
+
+	_, err = ParseString("\n@include \"missing/*.hcl\"", WithFilename("conf/main.hcl"), WithIncluder(fsys))
+	assert.EqualError(t, err, `conf/main.hcl:2:1: include "missing/*.hcl" matched no files`)
+
+	_, err = ParseString(`@include "../outside.hcl"`, WithFilename("main.hcl"), WithIncluder(fsys))
+	assert.EqualError(t, err, `main.hcl:1:1: invalid include "../outside.hcl", paths must be within the includer's file system`)
+}
+
+func TestIncludeCycle(t *testing.T) {
+	files := map[string]string{
+		"a.hcl": "@include \"b.hcl\"\n",
+		"b.hcl": "x = 1\n@include \"a.hcl\"\n",
+	}
+	fsys := fstest.MapFS{}
+	for name, content := range files {
+		fsys[name] = &fstest.MapFile{Data: []byte(content)}
+	}
+	_, err := ParseString(files["a.hcl"], WithFilename("a.hcl"), WithIncluder(fsys))
+	assert.EqualError(t, err, `b.hcl:2:1: include cycle a.hcl -> b.hcl -> a.hcl`)
+}
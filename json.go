@@ -0,0 +1,501 @@
+package hcl
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/alecthomas/participle/v2"
+)
+
+// Reserved keys in the JSON representation of HCL.
+const (
+	// JSONLabelsKey is the reserved key holding the labels of a block, which
+	// distinguishes blocks from maps.
+	JSONLabelsKey = "__labels__"
+	// JSONCommentsKey is the reserved key holding comments.
+	//
+	// Its value is an object mapping attribute keys to comment lines. The empty
+	// key holds the comments of the enclosing block, or the trailing comments
+	// of the AST at the top level.
+	JSONCommentsKey = "//"
+)
+
+// JSONOption configures MarshalASTToJSON.
+type JSONOption func(*jsonConfig)
+
+type jsonConfig struct {
+	comments bool
+}
+
+// JSONComments controls whether comments are included under the reserved
+// JSONCommentsKey. If false (default), comments are dropped.
+func JSONComments(v bool) JSONOption {
+	return func(config *jsonConfig) {
+		config.comments = v
+	}
+}
+
+// MarshalASTToJSON converts an AST to JSON.
+//
+// The root of the AST and each block body are JSON objects. Attributes are
+// properties holding their value, with heredocs converted to strings. Blocks
+// are properties holding an array of objects, one per block, with labels
+// stored under the reserved JSONLabelsKey, which is present even if the block
+// has no labels.
+//
+// Detached comments and comments on map entries are always dropped.
+func MarshalASTToJSON(ast *AST, options ...JSONOption) ([]byte, error) {
+	config := &jsonConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	obj, err := config.entriesToJSON(ast.Entries, ast.TrailingComments)
+	if err != nil {
+		return nil, err
+	}
+	w := &bytes.Buffer{}
+	writeJSON(w, obj)
+	return w.Bytes(), nil
+}
+
+// ParseJSON parses the JSON representation produced by MarshalASTToJSON into an AST.
+//
+// Arrays whose elements are all objects with a JSONLabelsKey member are converted
+// to blocks, other objects are converted to maps, and multi-line strings are
+// converted to heredocs.
+func ParseJSON(data []byte, options ...ParseOption) (*AST, error) {
+	config := &parseConfig{}
+	for _, option := range options {
+		option(config)
+	}
+	p := &jsonParser{data: data, filename: config.filename}
+	p.dec = json.NewDecoder(bytes.NewReader(data))
+	p.dec.UseNumber()
+	root, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+	offset := p.dec.InputOffset()
+	if _, err := p.dec.Token(); err != io.EOF {
+		return nil, participle.Errorf(p.pos(offset), "unexpected data after top-level object")
+	}
+	obj, ok := root.value.(jsonObject)
+	if !ok {
+		return nil, participle.Errorf(root.pos, "expected a top-level object")
+	}
+	ast := &AST{Pos: root.pos}
+	ast.Entries, ast.TrailingComments, err = jsonToEntries(obj, false)
+	if err != nil {
+		return nil, err
+	}
+	if err := AddParentRefs(ast); err != nil {
+		return nil, err
+	}
+	return ast, nil
+}
+
+// An ordered JSON object.
+type jsonObject []jsonMember
+
+type jsonMember struct {
+	key   string
+	value interface{}
+}
+
+func (o jsonObject) index(key string) int {
+	for i, member := range o {
+		if member.key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// A JSON array of block objects.
+type jsonBlocks []interface{}
+
+func (config *jsonConfig) entriesToJSON(entries []Entry, comments []string) (jsonObject, error) {
+	obj := jsonObject{}
+	commentsObj := jsonObject{}
+	if config.comments && len(comments) > 0 {
+		commentsObj = append(commentsObj, jsonMember{"", comments})
+	}
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case *Attribute:
+			if obj.index(entry.Key) >= 0 {
+				return nil, participle.Errorf(entry.Pos, "duplicate attribute %q", entry.Key)
+			}
+			value, err := valueToJSON(entry.Value)
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{entry.Key, value})
+			// JSON has no notion of a comment on the same line, so line
+			// comments follow the attribute's other comments.
+			lines := append([]string{}, entry.Comments...)
+			if entry.LineComment != "" {
+				lines = append(lines, entry.LineComment)
+			}
+			if config.comments && len(lines) > 0 {
+				commentsObj = append(commentsObj, jsonMember{entry.Key, lines})
+			}
+
+		case *Block:
+			// Labels are always present, as they mark the object as a block.
+			labels := entry.Labels
+			if labels == nil {
+				labels = []string{}
+			}
+			block := jsonObject{{JSONLabelsKey, labels}}
+			body, err := config.entriesToJSON(entry.Body, entry.Comments)
+			if err != nil {
+				return nil, err
+			}
+			block = append(block, body...)
+			index := obj.index(entry.Name)
+			if index < 0 {
+				obj = append(obj, jsonMember{entry.Name, jsonBlocks{block}})
+				continue
+			}
+			blocks, ok := obj[index].value.(jsonBlocks)
+			if !ok {
+				return nil, participle.Errorf(entry.Pos, "%s cannot be both block and attribute", entry.Name)
+			}
+			obj[index].value = append(blocks, block)
+		}
+	}
+	if len(commentsObj) > 0 {
+		obj = append(jsonObject{{JSONCommentsKey, commentsObj}}, obj...)
+	}
+	return obj, nil
+}
+
+func valueToJSON(value Value) (interface{}, error) {
+	switch value := value.(type) {
+	case nil:
+		// Bare attribute.
+		return true, nil
+	case *String:
+		return value.Str, nil
+	case *Heredoc:
+		return value.GetHeredoc(), nil
+	case *Number:
+		return json.Number(value.Float.Text('g', -1)), nil
+	case *Bool:
+		return value.Bool, nil
+	case *Type:
+		return value.String(), nil
+	case *List:
+		out := make([]interface{}, 0, len(value.List))
+		for _, el := range value.List {
+			v, err := valueToJSON(el)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, v)
+		}
+		return out, nil
+	case *Map:
+		out := jsonObject{}
+		for _, entry := range value.Entries {
+			key := entry.Key.String()
+			if str, ok := entry.Key.(*String); ok {
+				key = str.Str
+			}
+			v, err := valueToJSON(entry.Value)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, jsonMember{key, v})
+		}
+		return out, nil
+	default:
+		return nil, participle.Errorf(value.Position(), "can't convert %T to JSON", value)
+	}
+}
+
+func writeJSON(w *bytes.Buffer, v interface{}) {
+	switch v := v.(type) {
+	case jsonObject:
+		w.WriteByte('{')
+		for i, member := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeJSON(w, member.key)
+			w.WriteByte(':')
+			writeJSON(w, member.value)
+		}
+		w.WriteByte('}')
+	case jsonBlocks:
+		writeJSON(w, []interface{}(v))
+	case []interface{}:
+		w.WriteByte('[')
+		for i, el := range v {
+			if i > 0 {
+				w.WriteByte(',')
+			}
+			writeJSON(w, el)
+		}
+		w.WriteByte(']')
+	default:
+		// Scalars and string slices can't fail.
+		data, _ := json.Marshal(v)
+		w.Write(data)
+	}
+}
+
+// A JSON value decoded by jsonParser, along with its position.
+type jsonValue struct {
+	pos   Position
+	value interface{} // jsonObject of *jsonValue, []*jsonValue, string, json.Number, bool or nil
+}
+
+// jsonParser decodes JSON while preserving key order and positions.
+type jsonParser struct {
+	data     []byte
+	filename string
+	dec      *json.Decoder
+}
+
+func (p *jsonParser) parseValue() (*jsonValue, error) {
+	offset := p.dec.InputOffset()
+	token, err := p.dec.Token()
+	if err != nil {
+		return nil, p.wrapError(err)
+	}
+	value := &jsonValue{pos: p.pos(offset)}
+	switch token {
+	case json.Delim('{'):
+		obj := jsonObject{}
+		for p.dec.More() {
+			token, err := p.dec.Token()
+			if err != nil {
+				return nil, p.wrapError(err)
+			}
+			member, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			obj = append(obj, jsonMember{token.(string), member})
+		}
+		value.value = obj
+	case json.Delim('['):
+		list := []*jsonValue{}
+		for p.dec.More() {
+			el, err := p.parseValue()
+			if err != nil {
+				return nil, err
+			}
+			list = append(list, el)
+		}
+		value.value = list
+	default:
+		value.value = token
+		return value, nil
+	}
+	// Consume the closing delimiter.
+	if _, err := p.dec.Token(); err != nil {
+		return nil, p.wrapError(err)
+	}
+	return value, nil
+}
+
+func (p *jsonParser) wrapError(err error) error {
+	offset := p.dec.InputOffset()
+	var syntaxErr *json.SyntaxError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.Is(err, io.EOF) {
+		err = io.ErrUnexpectedEOF
+	}
+	return participle.Wrapf(p.pos(offset), err, "invalid JSON")
+}
+
+// pos converts a decoder offset into a Position, skipping separators
+// between the previous token and the next.
+func (p *jsonParser) pos(offset int64) Position {
+	for offset < int64(len(p.data)) && strings.IndexByte(" \t\r\n:,", p.data[offset]) >= 0 {
+		offset++
+	}
+	pos := Position{Filename: p.filename, Offset: int(offset), Line: 1, Column: 1}
+	for _, b := range p.data[:offset] {
+		if b == '\n' {
+			pos.Line++
+			pos.Column = 1
+		} else {
+			pos.Column++
+		}
+	}
+	return pos
+}
+
+func jsonToEntries(obj jsonObject, inBlock bool) (entries Entries, comments CommentList, err error) {
+	attrComments := map[string]CommentList{}
+	if index := obj.index(JSONCommentsKey); index >= 0 {
+		value := obj[index].value.(*jsonValue)
+		commentsObj, ok := value.value.(jsonObject)
+		if !ok {
+			return nil, nil, participle.Errorf(value.pos, "%q must be an object", JSONCommentsKey)
+		}
+		for _, member := range commentsObj {
+			lines, err := jsonToStrings(member.value.(*jsonValue))
+			if err != nil {
+				return nil, nil, err
+			}
+			attrComments[member.key] = lines
+		}
+	}
+	for _, member := range obj {
+		value := member.value.(*jsonValue)
+		switch member.key {
+		case JSONCommentsKey:
+			continue
+		case JSONLabelsKey:
+			if !inBlock {
+				return nil, nil, participle.Errorf(value.pos, "%q is only valid in a block", JSONLabelsKey)
+			}
+			continue
+		}
+		if blocks, ok := jsonBlockList(value); ok {
+			for _, el := range blocks {
+				block, err := jsonToBlock(member.key, el)
+				if err != nil {
+					return nil, nil, err
+				}
+				entries = append(entries, block)
+			}
+			continue
+		}
+		hvalue, err := jsonToValue(value)
+		if err != nil {
+			return nil, nil, err
+		}
+		entries = append(entries, &Attribute{
+			Pos:      value.pos,
+			Key:      member.key,
+			Value:    hvalue,
+			Comments: attrComments[member.key],
+		})
+	}
+	return entries, attrComments[""], nil
+}
+
+// jsonBlockList returns the elements of value if it is a non-empty array of
+// objects that are all marked as blocks by JSONLabelsKey.
+func jsonBlockList(value *jsonValue) ([]*jsonValue, bool) {
+	list, ok := value.value.([]*jsonValue)
+	if !ok || len(list) == 0 {
+		return nil, false
+	}
+	for _, el := range list {
+		if obj, ok := el.value.(jsonObject); !ok || obj.index(JSONLabelsKey) < 0 {
+			return nil, false
+		}
+	}
+	return list, true
+}
+
+func jsonToBlock(name string, value *jsonValue) (*Block, error) {
+	obj := value.value.(jsonObject)
+	block := &Block{Pos: value.pos, Name: name}
+	labels, err := jsonToStrings(obj[obj.index(JSONLabelsKey)].value.(*jsonValue))
+	if err != nil {
+		return nil, err
+	}
+	if len(labels) > 0 {
+		block.Labels = labels
+	}
+	block.Body, block.Comments, err = jsonToEntries(obj, true)
+	return block, err
+}
+
+func jsonToStrings(value *jsonValue) ([]string, error) {
+	if str, ok := value.value.(string); ok {
+		return []string{str}, nil
+	}
+	list, ok := value.value.([]*jsonValue)
+	if !ok {
+		return nil, participle.Errorf(value.pos, "expected a string or array of strings")
+	}
+	out := make([]string, 0, len(list))
+	for _, el := range list {
+		str, ok := el.value.(string)
+		if !ok {
+			return nil, participle.Errorf(el.pos, "expected a string")
+		}
+		out = append(out, str)
+	}
+	return out, nil
+}
+
+func jsonToValue(value *jsonValue) (Value, error) {
+	switch v := value.value.(type) {
+	case string:
+		if strings.Contains(v, "\n") {
+			return &Heredoc{Pos: value.pos, Delimiter: heredocDelimiter(v), Doc: "\n" + v}, nil
+		}
+		return &String{Pos: value.pos, Str: v}, nil
+	case json.Number:
+		f, _, err := big.ParseFloat(string(v), 10, 0, big.ToNearestEven)
+		if err != nil {
+			return nil, participle.Wrapf(value.pos, err, "invalid number")
+		}
+		return &Number{Pos: value.pos, Float: f}, nil
+	case bool:
+		return &Bool{Pos: value.pos, Bool: v}, nil
+	case []*jsonValue:
+		list := &List{Pos: value.pos, List: []Value{}}
+		for _, el := range v {
+			hvalue, err := jsonToValue(el)
+			if err != nil {
+				return nil, err
+			}
+			list.List = append(list.List, hvalue)
+		}
+		return list, nil
+	case jsonObject:
+		m := &Map{Pos: value.pos}
+		for _, member := range v {
+			el := member.value.(*jsonValue)
+			hvalue, err := jsonToValue(el)
+			if err != nil {
+				return nil, err
+			}
+			m.Entries = append(m.Entries, &MapEntry{
+				Pos:   el.pos,
+				Key:   &String{Pos: el.pos, Str: member.key},
+				Value: hvalue,
+			})
+		}
+		return m, nil
+	default:
+		return nil, participle.Errorf(value.pos, "null values are not supported")
+	}
+}
+
+// heredocDelimiter returns a delimiter that does not terminate doc early.
+func heredocDelimiter(doc string) string {
+	for i := 0; ; i++ {
+		delimiter := "EOF"
+		if i > 0 {
+			delimiter += strconv.Itoa(i)
+		}
+		conflict := false
+		for _, line := range strings.Split(doc, "\n") {
+			if fields := strings.Fields(line); len(fields) > 0 && strings.HasPrefix(fields[0], delimiter) {
+				conflict = true
+				break
+			}
+		}
+		if !conflict {
+			return delimiter
+		}
+	}
+}
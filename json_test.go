@@ -0,0 +1,122 @@
+package hcl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestMarshalASTToJSON(t *testing.T) {
+	ast, err := ParseString(`
+		// Attribute comment.
+		name = "app"
+		ports = [80, 443]
+		labels = {
+			"env": "prod",
+		}
+		doc = <<EOF
+hello
+world
+EOF
+
+		// Block comment.
+		service "web" {
+			replicas = 2
+		}
+
+		service "db" {}
+		empty {}
+	`)
+	assert.NoError(t, err)
+
+	data, err := MarshalASTToJSON(ast)
+	assert.NoError(t, err)
+	assert.Equal(t,
+		`{"name":"app","ports":[80,443],"labels":{"env":"prod"},"doc":"hello\nworld",`+
+			`"service":[{"__labels__":["web"],"replicas":2},{"__labels__":["db"]}],"empty":[{"__labels__":[]}]}`,
+		string(data))
+
+	data, err = MarshalASTToJSON(ast, JSONComments(true))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), `{"//":{"name":["Attribute comment."]},`), string(data))
+	assert.Contains(t, string(data), `{"__labels__":["web"],"//":{"":["Block comment."]},"replicas":2}`)
+}
+
+func TestMarshalASTToJSONConflict(t *testing.T) {
+	ast, err := ParseString(`
+		service = 1
+		service {}
+	`)
+	assert.NoError(t, err)
+	_, err = MarshalASTToJSON(ast)
+	assert.EqualError(t, err, "3:3: service cannot be both block and attribute")
+}
+
+func TestJSONRoundTrip(t *testing.T) {
+	const source = `// Attribute comment.
+name = "app"
+ports = [80, 443]
+labels = {
+  "env": "prod",
+}
+doc = <<EOF
+hello
+world
+EOF
+
+// Block comment.
+service web {
+  replicas = 2
+}
+
+service db {}
+
+// List comment.
+b = [{"k": 1}]
+`
+	ast, err := ParseString(source)
+	assert.NoError(t, err)
+	data, err := MarshalASTToJSON(ast, JSONComments(true))
+	assert.NoError(t, err)
+
+	ast, err = ParseJSON(data)
+	assert.NoError(t, err)
+	actual, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, source, string(actual))
+}
+
+func TestParseJSONListOfObjects(t *testing.T) {
+	ast, err := ParseJSON([]byte(`{"b": [{"k": 1}], "c": [{"__labels__": [], "k": 1}]}`))
+	assert.NoError(t, err)
+	actual, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, "b = [{\"k\": 1}]\n\nc {\n  k = 1\n}\n", string(actual))
+}
+
+func TestParseJSONErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		fail string
+	}{
+		{name: "NotObject", json: `[1]`, fail: "config.json:1:1: expected a top-level object"},
+		{name: "Null", json: "{\n  \"a\": null\n}", fail: "config.json:2:8: null values are not supported"},
+		{name: "TrailingData", json: `{} {}`, fail: "config.json:1:4: unexpected data after top-level object"},
+		{name: "TopLevelLabels", json: `{"__labels__": ["a"]}`, fail: `config.json:1:16: "__labels__" is only valid in a block`},
+		{name: "BadLabels", json: `{"a": [{"__labels__": 1}]}`, fail: "config.json:1:23: expected a string or array of strings"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ParseJSON([]byte(test.json), WithFilename("config.json"))
+			assert.EqualError(t, err, test.fail)
+		})
+	}
+
+	// The wording of syntax errors varies between Go releases.
+	_, err := ParseJSON([]byte("{\n  \"a\": }"), WithFilename("config.json"))
+	assert.Error(t, err)
+	assert.True(t, strings.HasPrefix(err.Error(), "config.json:2:"), err.Error())
+	assert.Contains(t, err.Error(), "invalid JSON")
+}
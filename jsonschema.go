@@ -0,0 +1,203 @@
+package hcl
+
+import (
+	"bytes"
+	"strings"
+)
+
+// JSONSchema reflects a JSON Schema from a Go value, describing the JSON
+// representation of its HCL produced by MarshalASTToJSON.
+//
+// This is useful for editor completion and validation. The schema is that of
+// Schema, with descriptions from help:"" tags. Blocks are arrays of objects,
+// with labels under the required JSONLabelsKey, and recursive blocks refer to definitions
+// named after their Go types, as with Schema. Aliases are properties with the
+// schema of their entry, and deprecated keys are marked "deprecated".
+func JSONSchema(v interface{}, options ...MarshalOption) ([]byte, error) {
+	ast, err := Schema(v, options...)
+	if err != nil {
+		return nil, err
+	}
+	types := schemaDefinitions(ast)
+	g := &jsonSchemaGenerator{
+		types:   types,
+		defined: map[string]bool{},
+	}
+	root := g.object(withoutDefinitions(ast.Entries, types), false, nil)
+	out := append(jsonObject{{"$schema", "http://json-schema.org/draft-07/schema#"}}, root...)
+	if len(g.definitions) > 0 {
+		out = append(out, jsonMember{"definitions", g.definitions})
+	}
+	w := &bytes.Buffer{}
+	writeJSON(w, out)
+	return w.Bytes(), nil
+}
+
+type jsonSchemaGenerator struct {
+	types       map[string]*Block
+	defined     map[string]bool
+	definitions jsonObject
+}
+
+// object returns the schema of the root or, if block is true, a block body.
+func (g *jsonSchemaGenerator) object(entries []Entry, block bool, labels []string) jsonObject {
+	properties := jsonObject{}
+	required := []interface{}{}
+	if block {
+		labelsSchema := jsonObject{}
+		if len(labels) > 0 {
+			labelsSchema = append(labelsSchema, jsonMember{"description", "Labels: " + strings.Join(labels, ", ")})
+		}
+		labelsSchema = append(labelsSchema,
+			jsonMember{"type", "array"},
+			jsonMember{"items", jsonObject{{"type", "string"}}},
+			jsonMember{"minItems", len(labels)},
+			jsonMember{"maxItems", len(labels)},
+		)
+		properties = append(properties, jsonMember{JSONLabelsKey, labelsSchema})
+		required = append(required, JSONLabelsKey)
+	}
+	// Required attributes with aliases must be present under any one of their keys.
+	alternatives := []interface{}{}
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case *Attribute:
+			properties = append(properties, aliasedProperties(entry.Key, entry.Aliases, entry.Deprecated, g.attribute(entry))...)
+			if entry.Optional || entry.Default != nil {
+				continue
+			}
+			if len(entry.Aliases) == 0 {
+				required = append(required, entry.Key)
+				continue
+			}
+			anyOf := []interface{}{jsonObject{{"required", []interface{}{entry.Key}}}}
+			for _, alias := range entry.Aliases {
+				anyOf = append(anyOf, jsonObject{{"required", []interface{}{alias}}})
+			}
+			alternatives = append(alternatives, jsonObject{{"anyOf", anyOf}})
+
+		case *Block:
+			properties = append(properties, aliasedProperties(entry.Name, entry.Aliases, entry.Deprecated, g.block(entry))...)
+		}
+	}
+	out := jsonObject{{"type", "object"}, {"properties", properties}}
+	if len(required) > 0 {
+		out = append(out, jsonMember{"required", required})
+	}
+	if len(alternatives) > 0 {
+		out = append(out, jsonMember{"allOf", alternatives})
+	}
+	return append(out, jsonMember{"additionalProperties", false})
+}
+
+// aliasedProperties returns the properties of an entry and its aliases, which
+// share its schema and are marked deprecated as in Unmarshal.
+func aliasedProperties(key string, aliases []string, deprecated string, schema jsonObject) jsonObject {
+	markDeprecated := func(schema jsonObject) jsonObject {
+		return append(append(jsonObject{}, schema...), jsonMember{"deprecated", true})
+	}
+	if deprecated != "" && len(aliases) == 0 {
+		schema = markDeprecated(schema)
+	}
+	properties := jsonObject{{key, schema}}
+	for _, alias := range aliases {
+		aliasSchema := schema
+		if deprecated != "" {
+			aliasSchema = markDeprecated(schema)
+		}
+		properties = append(properties, jsonMember{alias, aliasSchema})
+	}
+	return properties
+}
+
+func (g *jsonSchemaGenerator) block(block *Block) jsonObject {
+	out := jsonObject{}
+	if len(block.Comments) > 0 {
+		out = append(out, jsonMember{"description", strings.Join(block.Comments, "\n")})
+	}
+	out = append(out, jsonMember{"type", "array"})
+	var items jsonObject
+	if block.Ref != "" {
+		g.define(block.Ref, block.Labels)
+		items = jsonObject{{"$ref", "#/definitions/" + block.Ref}}
+	} else {
+		items = g.object(block.Body, true, block.Labels)
+	}
+	out = append(out, jsonMember{"items", items})
+	if !block.Repeated {
+		out = append(out, jsonMember{"maxItems", 1})
+	}
+	return out
+}
+
+// define adds the definition of a referenced type.
+func (g *jsonSchemaGenerator) define(name string, labels []string) {
+	definition, ok := g.types[name]
+	if !ok || g.defined[name] {
+		return
+	}
+	g.defined[name] = true
+	i := len(g.definitions)
+	g.definitions = append(g.definitions, jsonMember{name, nil})
+	g.definitions[i].value = g.object(definition.Body, true, labels)
+}
+
+func (g *jsonSchemaGenerator) attribute(attr *Attribute) jsonObject {
+	out := jsonObject{}
+	if len(attr.Comments) > 0 {
+		out = append(out, jsonMember{"description", strings.Join(attr.Comments, "\n")})
+	}
+	out = append(out, jsonValueSchema(attr.Value)...)
+	if attr.Default != nil {
+		if value, err := valueToJSON(attr.Default); err == nil {
+			out = append(out, jsonMember{"default", value})
+		}
+	}
+	if len(attr.Enum) > 0 {
+		enum := []interface{}{}
+		for _, value := range attr.Enum {
+			if value, err := valueToJSON(value); err == nil {
+				enum = append(enum, value)
+			}
+		}
+		out = append(out, jsonMember{"enum", enum})
+	}
+	return out
+}
+
+// jsonValueSchema returns the JSON Schema of a schema type.
+func jsonValueSchema(value Value) jsonObject {
+	switch value := value.(type) {
+	case *Type:
+		switch value.Type {
+		case durType:
+			return jsonObject{{"type", strType}}
+		case tsType:
+			return jsonObject{{"type", strType}, {"format", "date-time"}}
+		case anyType:
+			return jsonObject{}
+		case listType:
+			return jsonObject{{"type", "array"}, {"items", jsonValueSchema(value.Of)}}
+		case mapType:
+			return jsonObject{{"type", "object"}, {"additionalProperties", jsonValueSchema(value.Of)}}
+		}
+		if value.Of != nil {
+			return jsonValueSchema(value.Of)
+		}
+		return jsonObject{{"type", value.Type}}
+	case *List:
+		out := jsonObject{{"type", "array"}}
+		if len(value.List) == 1 {
+			out = append(out, jsonMember{"items", jsonValueSchema(value.List[0])})
+		}
+		return out
+	case *Map:
+		out := jsonObject{{"type", "object"}}
+		if len(value.Entries) == 1 {
+			out = append(out, jsonMember{"additionalProperties", jsonValueSchema(value.Entries[0].Value)})
+		}
+		return out
+	default:
+		return jsonObject{}
+	}
+}
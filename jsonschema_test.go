@@ -0,0 +1,83 @@
+package hcl
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestJSONSchema(t *testing.T) {
+	data, err := JSONSchema(&testSchema{})
+	assert.NoError(t, err)
+	schema := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &schema))
+	assert.Equal(t, "http://json-schema.org/draft-07/schema#", schema["$schema"].(string))
+	assert.Equal(t, []interface{}{"str", "bool", "list", "map", "map2", "enum_str"}, schema["required"].([]interface{}))
+	assert.Equal(t, false, schema["additionalProperties"].(bool))
+
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{
+		"description": "A string field.",
+		"type":        "string",
+	}, properties["str"].(map[string]interface{}))
+	assert.Equal(t, map[string]interface{}{
+		"type":  "array",
+		"items": map[string]interface{}{"type": "string"},
+	}, properties["list"].(map[string]interface{}))
+	assert.Equal(t, map[string]interface{}{
+		"description":          "A map.",
+		"type":                 "object",
+		"additionalProperties": map[string]interface{}{"type": "number"},
+	}, properties["map"].(map[string]interface{}))
+	assert.Equal(t, map[string]interface{}{"type": "string", "default": "def"}, properties["default_str"].(map[string]interface{}))
+	assert.Equal(t, map[string]interface{}{"type": "string", "enum": []interface{}{"a", "b", "c"}}, properties["enum_str"].(map[string]interface{}))
+
+	block := properties["block"].(map[string]interface{})
+	assert.Equal(t, "A block.", block["description"].(string))
+	assert.Equal(t, 1.0, block["maxItems"].(float64))
+	items := block["items"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"__labels__", "attr"}, items["required"].([]interface{}))
+	labels := items["properties"].(map[string]interface{})["__labels__"].(map[string]interface{})
+	assert.Equal(t, 1.0, labels["minItems"].(float64))
+	assert.Equal(t, 1.0, labels["maxItems"].(float64))
+
+	_, ok := properties["block_slice"].(map[string]interface{})["maxItems"]
+	assert.False(t, ok)
+}
+
+func TestJSONSchemaRecursive(t *testing.T) {
+	data, err := JSONSchema(&RecursiveSchema{})
+	assert.NoError(t, err)
+	schema := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &schema))
+	recursive := schema["properties"].(map[string]interface{})["recursive"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"$ref": "#/definitions/RecursiveSchema"}, recursive["items"].(map[string]interface{}))
+	definition := schema["definitions"].(map[string]interface{})["RecursiveSchema"].(map[string]interface{})
+	recursive = definition["properties"].(map[string]interface{})["recursive"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"$ref": "#/definitions/RecursiveSchema"}, recursive["items"].(map[string]interface{}))
+	labels := definition["properties"].(map[string]interface{})["__labels__"].(map[string]interface{})
+	assert.Equal(t, 0.0, labels["maxItems"].(float64))
+}
+
+func TestJSONSchemaAliases(t *testing.T) {
+	data, err := JSONSchema(&aliasedConfig{})
+	assert.NoError(t, err)
+	schema := map[string]interface{}{}
+	assert.NoError(t, json.Unmarshal(data, &schema))
+	properties := schema["properties"].(map[string]interface{})
+	assert.Equal(t, map[string]interface{}{"type": "string"}, properties["bind"].(map[string]interface{}))
+	assert.Equal(t, map[string]interface{}{"type": "string", "deprecated": true}, properties["listen_addr"].(map[string]interface{}))
+	assert.Equal(t, map[string]interface{}{"type": "string", "deprecated": true}, properties["addr"].(map[string]interface{}))
+	assert.Equal(t, map[string]interface{}{"type": "number", "deprecated": true}, properties["timeout"].(map[string]interface{}))
+	assert.Equal(t, properties["tls"], properties["ssl"])
+	_, ok := schema["required"]
+	assert.False(t, ok)
+	assert.Equal(t, []interface{}{
+		map[string]interface{}{"anyOf": []interface{}{
+			map[string]interface{}{"required": []interface{}{"bind"}},
+			map[string]interface{}{"required": []interface{}{"listen_addr"}},
+			map[string]interface{}{"required": []interface{}{"addr"}},
+		}},
+	}, schema["allOf"].([]interface{}))
+}
@@ -0,0 +1,545 @@
+package hcl
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/alecthomas/participle/v2/lexer"
+)
+
+// sourceMap retains the original source of an AST parsed WithLossless(true),
+// so that unmodified regions can be reproduced byte-for-byte.
+//
+// Modifications are detected by comparing the formatted form of each entry
+// with the form it had when parsed, so edits made by any means, including Set,
+// Delete and direct mutation, cause only the affected entries to be reformatted.
+type sourceMap struct {
+	data []byte
+	// Indentation of a nested body relative to its parent, from the first
+	// indented block body in the source, or empty if there is none.
+	indent string
+	// Line ending of the source, used for formatted entries.
+	newline string
+	entries map[Entry]*entrySource
+	bodies  map[Node]*bodySource
+}
+
+// entrySource is the original source of an entry.
+type entrySource struct {
+	container Node
+	// Original predecessor within container, or nil.
+	prev Entry
+	// Start of the whitespace and comments between prev (or the start of the body) and the entry.
+	leadingStart int
+	// Start of the line containing the entry's attached comments, or -1 if
+	// the entry does not start its own line.
+	attachedStart int
+	start, end    int
+	comments      string
+	content       string
+}
+
+// bodySource is the original source of the AST or of a block body.
+type bodySource struct {
+	indent string
+	// True if indent was taken from the source.
+	indented bool
+	// Offset just past the opening brace of a block, and any comment following it.
+	headerEnd int
+	header    string
+	// Closing brace of a block, and any comment following it.
+	closing    string
+	endComment string
+	// Original entries, in source order.
+	entries []Entry
+	// Original last entry, or nil.
+	last Entry
+	// Whitespace and comments between last (or the start of the body) and the
+	// closing brace or end of file.
+	trailingStart, trailingEnd int
+	trailingComments           string
+}
+
+var (
+	whitespaceType = lex.Symbols()["Whitespace"]
+	commentType    = lex.Symbols()["Comment"]
+	punctType      = lex.Symbols()["Punct"]
+)
+
+func newSourceMap(ast *AST, filename string, data []byte) (*sourceMap, error) {
+	tokenLexer, err := lex.Lex(filename, bytes.NewReader(data))
+	if err != nil {
+		return nil, err
+	}
+	tokens, err := lexer.ConsumeAll(tokenLexer)
+	if err != nil {
+		return nil, err
+	}
+	s := &sourceMap{
+		data:    data,
+		newline: "\n",
+		entries: map[Entry]*entrySource{},
+		bodies:  map[Node]*bodySource{},
+	}
+	if i := bytes.IndexByte(data, '\n'); i > 0 && data[i-1] == '\r' {
+		s.newline = "\r\n"
+	}
+	s.addBody(tokens, ast, ast.Entries, ast.TrailingComments, 0, len(data))
+	for _, block := range blocksInSourceOrder(ast) {
+		body := s.bodies[block]
+		parent := s.bodies[block.Parent]
+		if body.indented && parent != nil && len(body.indent) > len(parent.indent) && strings.HasPrefix(body.indent, parent.indent) {
+			s.indent = body.indent[len(parent.indent):]
+			break
+		}
+	}
+	return s, nil
+}
+
+// addBody records the source of a body spanning [start, end) and its entries.
+func (s *sourceMap) addBody(tokens []lexer.Token, container Node, entries []Entry, trailing []string, start, end int) *bodySource {
+	body := &bodySource{trailingComments: joinComments(trailing)}
+	if len(entries) > 0 {
+		body.indent, body.indented = s.lineIndent(entries[0].Position().Offset)
+	}
+	s.bodies[container] = body
+	prevEnd := start
+	var prev Entry
+	for i, entry := range entries {
+		boundary := end
+		if i+1 < len(entries) {
+			boundary = entries[i+1].Position().Offset
+		}
+		entryStart := entry.Position().Offset
+		var entryEnd int
+		if _, ok := entry.(*Comment); ok {
+			token := tokens[tokenIndex(tokens, entryStart)]
+			entryEnd = entryStart + len(token.Value)
+		} else {
+			entryEnd = lineCommentEnd(tokens, lastTokenEnd(tokens, entryStart, boundary))
+		}
+		s.entries[entry] = &entrySource{
+			container:     container,
+			prev:          prev,
+			leadingStart:  prevEnd,
+			attachedStart: s.attachedStart(tokens, entry),
+			start:         entryStart,
+			end:           entryEnd,
+			comments:      joinComments(entryComments(entry)),
+			content:       entryContent(entry),
+		}
+		if block, ok := entry.(*Block); ok {
+			headerEnd := lineCommentEnd(tokens, openingBraceEnd(tokens, entryStart))
+			closingStart := lastTokenEnd(tokens, entryStart, boundary) - 1
+			blockBody := s.addBody(tokens, block, block.Body, block.TrailingComments, headerEnd, closingStart)
+			blockBody.headerEnd = headerEnd
+			blockBody.header = blockHeader(block, newMarshalState())
+			blockBody.closing = string(s.data[closingStart:entryEnd])
+			blockBody.endComment = block.EndComment
+		}
+		prev = entry
+		prevEnd = entryEnd
+	}
+	body.entries = append([]Entry{}, entries...)
+	body.last = prev
+	body.trailingStart = prevEnd
+	body.trailingEnd = end
+	return body
+}
+
+// attachedStart returns the start of the line on which the entry, or its
+// attached comments, begin.
+func (s *sourceMap) attachedStart(tokens []lexer.Token, entry Entry) int {
+	offset := entry.Position().Offset
+	if len(entryComments(entry)) > 0 {
+		if i := tokenIndex(tokens, offset); i >= 2 && tokens[i-2].Type == commentType {
+			offset = tokens[i-2].Pos.Offset
+		}
+	}
+	if _, ok := s.lineIndent(offset); !ok {
+		return -1
+	}
+	return bytes.LastIndexByte(s.data[:offset], '\n') + 1
+}
+
+// lineIndent returns the whitespace preceding offset on its line, and false
+// if the line has other content before offset.
+func (s *sourceMap) lineIndent(offset int) (string, bool) {
+	lineStart := bytes.LastIndexByte(s.data[:offset], '\n') + 1
+	indent := string(s.data[lineStart:offset])
+	if strings.TrimLeft(indent, " \t") != "" {
+		return "", false
+	}
+	return indent, true
+}
+
+func blocksInSourceOrder(ast *AST) []*Block {
+	blocks := []*Block{}
+	_ = visitBlocks(ast, func(block *Block) error {
+		blocks = append(blocks, block)
+		return nil
+	})
+	return blocks
+}
+
+func tokenIndex(tokens []lexer.Token, offset int) int {
+	return sort.Search(len(tokens), func(i int) bool { return tokens[i].Pos.Offset >= offset })
+}
+
+// lastTokenEnd returns the end offset of the last token in [start, boundary)
+// that is not whitespace or a comment.
+func lastTokenEnd(tokens []lexer.Token, start, boundary int) int {
+	end := start
+	for i := tokenIndex(tokens, start); i < len(tokens) && tokens[i].Pos.Offset < boundary; i++ {
+		if tokens[i].Type != whitespaceType && tokens[i].Type != commentType {
+			end = tokens[i].Pos.Offset + len(tokens[i].Value)
+		}
+	}
+	return end
+}
+
+// lineCommentEnd returns the end of a comment on the same line as offset, or
+// offset if there is none.
+func lineCommentEnd(tokens []lexer.Token, offset int) int {
+	i := tokenIndex(tokens, offset)
+	if i < len(tokens) && tokens[i].Type == whitespaceType && !strings.Contains(tokens[i].Value, "\n") {
+		i++
+	}
+	if i >= len(tokens) || tokens[i].Type != commentType {
+		return offset
+	}
+	comment := tokens[i].Value
+	if newline := strings.IndexByte(comment, '\n'); newline >= 0 && !strings.HasPrefix(comment, "/*") {
+		comment = comment[:newline]
+	}
+	return tokens[i].Pos.Offset + len(comment)
+}
+
+// openingBraceEnd returns the offset just past the first "{" at or after start.
+func openingBraceEnd(tokens []lexer.Token, start int) int {
+	for i := tokenIndex(tokens, start); i < len(tokens); i++ {
+		if tokens[i].Type == punctType && tokens[i].Value == "{" {
+			return tokens[i].Pos.Offset + 1
+		}
+	}
+	return start
+}
+
+func joinComments(comments []string) string {
+	return strings.Join(comments, "\n")
+}
+
+func entryComments(entry Entry) []string {
+	switch entry := entry.(type) {
+	case *Attribute:
+		return entry.Comments
+	case *Block:
+		return entry.Comments
+	}
+	return nil
+}
+
+// entryContent returns the formatted form of an entry excluding its leading comments.
+func entryContent(entry Entry) string {
+	w := &strings.Builder{}
+	opt := newMarshalState()
+	switch entry := entry.(type) {
+	case *Attribute:
+		clone := *entry
+		clone.Comments = nil
+		_ = marshalAttribute(w, "", &clone, opt)
+	case *Block:
+		clone := *entry
+		clone.Comments = nil
+		_ = marshalBlock(w, "", &clone, opt)
+	case *Comment:
+		marshalComments(w, "", entry.Comments, opt)
+	}
+	return w.String()
+}
+
+func blockHeader(block *Block, opt *marshalState) string {
+	w := &strings.Builder{}
+	w.WriteString(block.Name)
+	if block.Repeated {
+		w.WriteString("(repeated)")
+	}
+	for _, label := range block.Labels {
+		fmt.Fprintf(w, " %s", opt.label(label))
+	}
+	w.WriteString(" {")
+	marshalLineComment(w, block.LineComment, opt)
+	return w.String()
+}
+
+func (s *sourceMap) marshal(w io.Writer, ast *AST, opt *marshalState) error {
+	if s.indent != "" {
+		// Format new entries with the indentation of the source.
+		clone := *opt
+		clone.indent = s.indent
+		opt = &clone
+	}
+	if s.newline != "\n" {
+		w = &newlineWriter{w: w, newline: s.newline}
+	}
+	return s.marshalBody(w, ast, "", ast.Entries, ast.TrailingComments, opt)
+}
+
+// copy writes the source in [start, end) to w unaltered.
+func (s *sourceMap) copy(w io.Writer, start, end int) {
+	if nw, ok := w.(*newlineWriter); ok {
+		w = nw.w
+		nw.cr = end > start && s.data[end-1] == '\r'
+	}
+	_, _ = w.Write(s.data[start:end])
+}
+
+// newlineWriter translates the line breaks of formatted output to the line
+// ending of the source.
+type newlineWriter struct {
+	w       io.Writer
+	newline string
+	// True if the last byte written was a carriage return.
+	cr bool
+}
+
+func (n *newlineWriter) Write(p []byte) (int, error) {
+	out := make([]byte, 0, len(p))
+	for _, b := range p {
+		if b == '\n' && !n.cr {
+			out = append(out, n.newline...)
+		} else {
+			out = append(out, b)
+		}
+		n.cr = b == '\r'
+	}
+	if _, err := n.w.Write(out); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+// marshalBody writes the entries of a body followed by its trailing comments.
+func (s *sourceMap) marshalBody(w io.Writer, container Node, parentIndent string, entries []Entry, trailing []string, opt *marshalState) error {
+	body := s.bodies[container]
+	_, isAST := container.(*AST)
+	indent := parentIndent + opt.indent
+	switch {
+	case body != nil && body.indented:
+		indent = body.indent
+	case isAST:
+		indent = ""
+	}
+	// Comments that stood alone before removed entries are written before the
+	// next entry in source order, or before the trailing text of the body.
+	// Moved entries keep theirs.
+	var (
+		prev     Entry
+		detached []string
+		// True if detached comments were written after prev.
+		written bool
+	)
+	present := map[Entry]bool{}
+	for _, entry := range entries {
+		present[entry] = true
+	}
+	done := map[Entry]bool{}
+	if body != nil && isAST && len(body.entries) > 0 && (len(entries) == 0 || entries[0] != body.entries[0]) {
+		// The file header stays at the top of the file.
+		if header := s.detachedComments(body.entries[0]); header != "" {
+			fmt.Fprint(w, header)
+			written = true
+		}
+		done[body.entries[0]] = true
+	}
+	for _, entry := range entries {
+		src := s.entries[entry]
+		unchanged := src != nil && src.container == container && src.comments == joinComments(entryComments(entry))
+		inPlace := unchanged && src.prev == prev
+		if k := body.index(entry); k >= 0 {
+			detached = s.removedComments(detached, body.entries[:k], present, done)
+			if !inPlace && !done[entry] {
+				detached = appendNonEmpty(detached, s.detachedComments(entry))
+			}
+			done[entry] = true
+		}
+		switch {
+		case inPlace:
+			s.copy(w, src.leadingStart, src.start)
+
+		default:
+			switch {
+			case len(detached) > 0:
+				writeDetached(w, detached, prev != nil || written, isAST)
+				fmt.Fprint(w, "\n\n")
+				detached = nil
+			case written:
+				fmt.Fprint(w, "\n\n")
+			case prev != nil || !isAST:
+				s.writeSeparator(w, prev, entry, opt)
+			}
+			if unchanged && src.attachedStart >= 0 {
+				s.copy(w, src.attachedStart, src.start)
+			} else {
+				marshalComments(w, indent, entryComments(entry), opt)
+				fmt.Fprint(w, indent)
+			}
+		}
+		if err := s.marshalEntry(w, container, indent, entry, opt); err != nil {
+			return err
+		}
+		prev = entry
+		written = false
+	}
+	if body != nil {
+		detached = s.removedComments(detached, body.entries, present, done)
+	}
+	if len(detached) > 0 {
+		writeDetached(w, detached, prev != nil || written, isAST)
+		written = true
+	}
+
+	if body != nil && (body.last == nil) == (prev == nil) && body.trailingComments == joinComments(trailing) {
+		s.copy(w, body.trailingStart, body.trailingEnd)
+	} else {
+		content := len(entries) > 0 || written
+		if content || len(trailing) > 0 {
+			fmt.Fprintln(w)
+		}
+		if len(trailing) > 0 {
+			if content {
+				fmt.Fprintln(w)
+			}
+			marshalComments(w, indent, trailing, opt)
+		}
+		if !isAST && (content || len(trailing) > 0) {
+			fmt.Fprint(w, parentIndent)
+		}
+	}
+	return nil
+}
+
+// detachedComments returns the comments standing alone between an original
+// entry and its predecessor, with their indentation, or "" if there are none.
+func (s *sourceMap) detachedComments(entry Entry) string {
+	src := s.entries[entry]
+	if src == nil || src.attachedStart < 0 {
+		return ""
+	}
+	gap := s.data[src.leadingStart:src.attachedStart]
+	first := bytes.IndexFunc(gap, func(r rune) bool { return !strings.ContainsRune(" \t\r\n", r) })
+	if first < 0 {
+		return ""
+	}
+	lineStart := bytes.LastIndexByte(gap[:first], '\n') + 1
+	return strings.TrimRight(string(gap[lineStart:]), " \t\r\n")
+}
+
+// removedComments appends the detached comments of the original entries that
+// are not present and not yet written.
+func (s *sourceMap) removedComments(out []string, originals []Entry, present, done map[Entry]bool) []string {
+	for _, original := range originals {
+		if !present[original] && !done[original] {
+			out = appendNonEmpty(out, s.detachedComments(original))
+			done[original] = true
+		}
+	}
+	return out
+}
+
+// index returns the index of entry in the original entries of the body, or -1.
+func (b *bodySource) index(entry Entry) int {
+	if b == nil {
+		return -1
+	}
+	for i, original := range b.entries {
+		if original == entry {
+			return i
+		}
+	}
+	return -1
+}
+
+// writeDetached writes detached comments separated from any preceding output
+// by a blank line, leaving the output at the end of the last comment.
+func writeDetached(w io.Writer, detached []string, following, isAST bool) {
+	if following || !isAST {
+		fmt.Fprintln(w)
+	}
+	if following {
+		fmt.Fprintln(w)
+	}
+	fmt.Fprint(w, strings.Join(detached, "\n\n"))
+}
+
+func appendNonEmpty(out []string, s string) []string {
+	if s == "" {
+		return out
+	}
+	return append(out, s)
+}
+
+// writeSeparator writes the line break, and blank line if the formatter would
+// add one, between prev and entry.
+func (s *sourceMap) writeSeparator(w io.Writer, prev, entry Entry, opt *marshalState) {
+	fmt.Fprintln(w)
+	if prev != nil && opt.blankLine(prev, entry) {
+		fmt.Fprintln(w)
+	}
+}
+
+// marshalEntry writes an entry without its leading comments, leading
+// indentation, or trailing newline.
+func (s *sourceMap) marshalEntry(w io.Writer, container Node, indent string, entry Entry, opt *marshalState) error {
+	src := s.entries[entry]
+	if src != nil && src.container == container && src.content == entryContent(entry) {
+		s.copy(w, src.start, src.end)
+		return nil
+	}
+	if block, ok := entry.(*Block); ok {
+		if body := s.bodies[block]; src != nil && body != nil && body.header == blockHeader(block, newMarshalState()) {
+			s.copy(w, src.start, body.headerEnd)
+			if err := s.marshalBody(w, block, indent, block.Body, block.TrailingComments, opt); err != nil {
+				return err
+			}
+			if block.EndComment == body.endComment {
+				fmt.Fprint(w, body.closing)
+			} else {
+				fmt.Fprint(w, "}")
+				marshalLineComment(w, block.EndComment, opt)
+			}
+			return nil
+		}
+		if len(block.Body) > 0 || len(block.TrailingComments) > 0 {
+			fmt.Fprint(w, blockHeader(block, opt))
+			if err := s.marshalBody(w, block, indent, block.Body, block.TrailingComments, opt); err != nil {
+				return err
+			}
+			fmt.Fprint(w, "}")
+			marshalLineComment(w, block.EndComment, opt)
+			return nil
+		}
+	}
+	buf := &strings.Builder{}
+	var err error
+	switch entry := entry.(type) {
+	case *Attribute:
+		clone := *entry
+		clone.Comments = nil
+		err = marshalAttribute(buf, indent, &clone, opt)
+	case *Block:
+		clone := *entry
+		clone.Comments = nil
+		err = marshalBlock(buf, indent, &clone, opt)
+	default:
+		err = marshalNode(buf, indent, entry, opt)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprint(w, strings.TrimSuffix(strings.TrimPrefix(buf.String(), indent), "\n"))
+	return nil
+}
@@ -0,0 +1,216 @@
+package hcl
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+const losslessHCL = `# Generated by hand.
+version   = '1.0.0'
+
+
+/* The web service. */
+service "web" {
+	port = 80   // HTTP
+	hosts = [
+		"a",
+		"b",
+	]
+
+	# Disabled.
+	// enabled = true
+	script = <<EOF
+echo hello
+EOF
+}
+
+service db {}
+// The end.
+`
+
+func TestLosslessRoundTrip(t *testing.T) {
+	for _, detached := range []bool{false, true} {
+		ast, err := ParseString(losslessHCL, WithLossless(true), WithDetachedComments(detached))
+		assert.NoError(t, err)
+		data, err := MarshalAST(ast)
+		assert.NoError(t, err)
+		assert.Equal(t, losslessHCL, string(data))
+	}
+}
+
+func TestLosslessReader(t *testing.T) {
+	ast, err := Parse(strings.NewReader("a   =  1\n"), WithLossless(true))
+	assert.NoError(t, err)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, "a   =  1\n", string(data))
+}
+
+func TestLosslessEdit(t *testing.T) {
+	ast, err := ParseString(losslessHCL, WithLossless(true))
+	assert.NoError(t, err)
+
+	assert.NoError(t, Set(ast, "version", str("1.1.0")))
+	assert.NoError(t, Set(ast, "service[web].port", num(8080)))
+	assert.NoError(t, Set(ast, "service[web].timeout", num(30)))
+	assert.NoError(t, Delete(ast, "service[web].script"))
+	assert.NoError(t, Set(ast, "service[db].port", num(5432)))
+	assert.NoError(t, Set(ast, "service[cache].port", num(6379)))
+
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `# Generated by hand.
+version = "1.1.0"
+
+
+/* The web service. */
+service "web" {
+	port = 8080 // HTTP
+	hosts = [
+		"a",
+		"b",
+	]
+	timeout = 30
+}
+
+service db {
+	port = 5432
+}
+
+service cache {
+	port = 6379
+}
+// The end.
+`, string(data))
+}
+
+func TestLosslessCRLF(t *testing.T) {
+	source := strings.ReplaceAll(losslessHCL, "\n", "\r\n")
+	ast, err := ParseString(source, WithLossless(true))
+	assert.NoError(t, err)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, source, string(data))
+
+	assert.NoError(t, Set(ast, "version", str("1.1.0")))
+	assert.NoError(t, Set(ast, "service[web].timeout", num(30)))
+	assert.NoError(t, Delete(ast, "service[web].script"))
+	assert.NoError(t, Set(ast, "service[cache].port", num(6379)))
+	data, err = MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, strings.ReplaceAll(`# Generated by hand.
+version = "1.1.0"
+
+
+/* The web service. */
+service "web" {
+	port = 80   // HTTP
+	hosts = [
+		"a",
+		"b",
+	]
+	timeout = 30
+}
+
+service db {}
+
+service cache {
+	port = 6379
+}
+// The end.
+`, "\n", "\r\n"), string(data))
+}
+
+func TestLosslessComments(t *testing.T) {
+	ast, err := ParseString(`
+# First.
+a = 1
+
+# Second.
+b = 2
+`, WithLossless(true))
+	assert.NoError(t, err)
+
+	a := ast.Entries[0].(*Attribute)
+	b := ast.Entries[1].(*Attribute)
+	b.Comments = []string{"Changed."}
+	assert.True(t, a.Detach())
+
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, "// Changed.\nb = 2\n", string(data))
+
+	// Reordering keeps attached comments with their entries.
+	ast, err = ParseString("# A.\na = 1\n# B.\nb = 2\n", WithLossless(true))
+	assert.NoError(t, err)
+	ast.Entries[0], ast.Entries[1] = ast.Entries[1], ast.Entries[0]
+	data, err = MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, "# B.\nb = 2\n# A.\na = 1\n", string(data))
+}
+
+func TestLosslessDisabled(t *testing.T) {
+	ast, err := ParseString("a   =  1\n")
+	assert.NoError(t, err)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, "a = 1\n", string(data))
+}
+
+func TestLosslessBlockEndComment(t *testing.T) {
+	source := "block { // open\n  a   = 1\n}   // close\n"
+	ast, err := ParseString(source, WithLossless(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "close", ast.Entries[0].(*Block).EndComment)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, source, string(data))
+
+	ast.Entries[0].(*Block).EndComment = "changed"
+	data, err = MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, "block { // open\n  a   = 1\n} // changed\n", string(data))
+}
+
+func TestLosslessDeleteDetachedComments(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		edit     func(ast *AST) error
+		expected string
+	}{
+		{"OnlyEntryUnderHeader", "// File header.\n\na = 1\n",
+			func(ast *AST) error { return Delete(ast, "a") },
+			"// File header.\n"},
+		{"FirstEntryUnderHeader", "// File header.\n\n// a doc\na = 1\nb = 2\n",
+			func(ast *AST) error { return Delete(ast, "a") },
+			"// File header.\n\nb = 2\n"},
+		{"DetachedAbove", "a = 1\n\n// Detached note.\n\n// b doc\nb = 2\n",
+			func(ast *AST) error { return Delete(ast, "b") },
+			"a = 1\n\n// Detached note.\n"},
+		{"DetachedAboveMiddle", "a = 1\n\n// Detached note.\n\n// b doc\nb = 2\nc = 3\n",
+			func(ast *AST) error { return Delete(ast, "b") },
+			"a = 1\n\n// Detached note.\n\nc = 3\n"},
+		{"InBlock", "block {\n  a = 1\n\n  // Detached note.\n\n  b = 2\n}\n",
+			func(ast *AST) error { return Delete(ast, "block.b") },
+			"block {\n  a = 1\n\n  // Detached note.\n}\n"},
+		{"Moved", "// File header.\n\na = 1\n\n// Section.\n\nb = 2\nc = 3\n",
+			func(ast *AST) error {
+				ast.Entries = Entries{ast.Entries[2], ast.Entries[0], ast.Entries[1]}
+				return nil
+			},
+			"// File header.\n\nc = 3\na = 1\n\n// Section.\n\nb = 2\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ast, err := ParseString(test.source, WithLossless(true))
+			assert.NoError(t, err)
+			assert.NoError(t, test.edit(ast))
+			data, err := MarshalAST(ast)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, string(data))
+		})
+	}
+}
@@ -28,6 +28,20 @@ type marshalState struct {
 	allowExtra           bool
 	implicitBlocks       bool
 	defaultTransformer   func(string) string
+	maxLineWidth         int
+	oneElementPerLine    bool
+	preserveLayout       bool
+	indent               string
+	commentMarker        string
+	labelWrapWidth       int
+	blankLines           BlankLines
+	quoteLabels          bool
+	singleQuotes         bool
+	provenance           Provenance
+	path                 string
+	types                *schemaTypes
+	warningHandler       func(Position, string)
+	migrations           *Migrations
 }
 
 // Create a shallow clone with schema overridden.
@@ -95,6 +109,20 @@ func HydratedImplicitBlocks(v bool) MarshalOption {
 	}
 }
 
+// WithWarningHandler calls handler with warnings found while unmarshalling,
+// such as uses of fields or aliases marked with deprecated:"" tags.
+func WithWarningHandler(handler func(Position, string)) MarshalOption {
+	return func(options *marshalState) {
+		options.warningHandler = handler
+	}
+}
+
+func (m *marshalState) warn(pos Position, msg string) {
+	if m.warningHandler != nil {
+		m.warningHandler(pos, msg)
+	}
+}
+
 // WithDefaultTransformer allows custom processing of default values in struct tags.
 func WithDefaultTransformer(transformer func(string) string) MarshalOption {
 	return func(options *marshalState) {
@@ -102,6 +130,100 @@ func WithDefaultTransformer(transformer func(string) string) MarshalOption {
 	}
 }
 
+// MaxLineWidth marshals lists and maps that would extend a line beyond n
+// columns with one element per line, and all others on a single line.
+//
+// A width of 0 disables the limit.
+func MaxLineWidth(n int) MarshalOption {
+	return func(options *marshalState) {
+		options.maxLineWidth = n
+	}
+}
+
+// OneElementPerLine marshals all non-empty lists and maps with one element per
+// line, each followed by a trailing comma.
+func OneElementPerLine(v bool) MarshalOption {
+	return func(options *marshalState) {
+		options.oneElementPerLine = v
+	}
+}
+
+// PreserveLayout marshals parsed lists and maps on a single line or multiple
+// lines as they were in the original source, taking precedence over
+// OneElementPerLine and MaxLineWidth.
+func PreserveLayout(v bool) MarshalOption {
+	return func(options *marshalState) {
+		options.preserveLayout = v
+	}
+}
+
+// FormatOption configures the output style of Marshal, MarshalAST,
+// MarshalASTToWriter and MarshalASTWithOptions.
+type FormatOption = MarshalOption
+
+// Indent sets the string each level of nesting is indented with. The default
+// is two spaces.
+func Indent(indent string) FormatOption {
+	return func(options *marshalState) {
+		options.indent = indent
+	}
+}
+
+// CommentMarker sets the marker comments are written with, "//" (the default)
+// or "#".
+func CommentMarker(marker string) FormatOption {
+	return func(options *marshalState) {
+		options.commentMarker = marker
+	}
+}
+
+// LabelWrapWidth sets the column at which block labels are wrapped onto the
+// following line. The default is 80, and a width of 0 disables wrapping.
+func LabelWrapWidth(n int) FormatOption {
+	return func(options *marshalState) {
+		options.labelWrapWidth = n
+	}
+}
+
+// BlankLines is a policy for separating entries with blank lines.
+//
+// Comments not attached to an entry are always separated by blank lines, as
+// they would otherwise become attached when parsed.
+type BlankLines int
+
+const (
+	// BlankLinesAroundBlocks separates blocks from other entries with blank
+	// lines, but not consecutive attributes. This is the default.
+	BlankLinesAroundBlocks BlankLines = iota
+	// BlankLinesBetweenEntries separates all entries with blank lines.
+	BlankLinesBetweenEntries
+	// NoBlankLines does not separate entries with blank lines.
+	NoBlankLines
+)
+
+// WithBlankLines sets the policy for separating entries with blank lines.
+func WithBlankLines(policy BlankLines) FormatOption {
+	return func(options *marshalState) {
+		options.blankLines = policy
+	}
+}
+
+// QuoteLabels quotes all block labels, rather than only labels that are not
+// valid identifiers.
+func QuoteLabels(v bool) FormatOption {
+	return func(options *marshalState) {
+		options.quoteLabels = v
+	}
+}
+
+// SingleQuotes quotes strings and labels with single quotes, unless they
+// contain a single quote.
+func SingleQuotes(v bool) FormatOption {
+	return func(options *marshalState) {
+		options.singleQuotes = v
+	}
+}
+
 func asSchema() MarshalOption {
 	return func(options *marshalState) {
 		options.schema = true
@@ -111,7 +233,10 @@ func asSchema() MarshalOption {
 // newMarshalState creates marshal options from a set of options
 func newMarshalState(options ...MarshalOption) *marshalState {
 	opt := &marshalState{
-		seenStructs: map[reflect.Type]bool{},
+		seenStructs:    map[reflect.Type]bool{},
+		indent:         "  ",
+		commentMarker:  "//",
+		labelWrapWidth: 80,
 	}
 	for _, option := range options {
 		option(opt)
@@ -125,7 +250,7 @@ func Marshal(v interface{}, options ...MarshalOption) ([]byte, error) {
 	if err != nil {
 		return nil, err
 	}
-	return MarshalAST(ast)
+	return MarshalAST(ast, options...)
 }
 
 // MarshalToAST marshals a Go type to a hcl.AST.
@@ -134,15 +259,27 @@ func MarshalToAST(v interface{}, options ...MarshalOption) (*AST, error) {
 }
 
 // MarshalAST marshals an AST to HCL bytes.
-func MarshalAST(ast Node) ([]byte, error) {
+//
+// Options that only affect marshalling Go values, such as
+// WithSchemaComments, are ignored. Layout options, such as PreserveLayout,
+// and FormatOptions, such as Indent and CommentMarker, apply as with Marshal.
+func MarshalAST(ast Node, options ...MarshalOption) ([]byte, error) {
 	w := &bytes.Buffer{}
-	err := MarshalASTToWriter(ast, w)
+	err := MarshalASTToWriter(ast, w, options...)
 	return w.Bytes(), err
 }
 
 // MarshalASTToWriter marshals a hcl.AST to an io.Writer.
-func MarshalASTToWriter(ast Node, w io.Writer) error {
-	return marshalNode(w, "", ast)
+func MarshalASTToWriter(ast Node, w io.Writer, options ...MarshalOption) error {
+	return marshalNode(w, "", ast, newMarshalState(options...))
+}
+
+// MarshalASTWithOptions marshals a hcl.AST to an io.Writer in the style
+// configured by options.
+//
+// It is equivalent to MarshalASTToWriter.
+func MarshalASTWithOptions(node Node, w io.Writer, options ...FormatOption) error {
+	return MarshalASTToWriter(node, w, options...)
 }
 
 func marshalToAST(v interface{}, opt *marshalState) (*AST, error) {
@@ -214,7 +351,13 @@ func structToEntries(v reflect.Value, opt *marshalState) (entries []Entry, label
 			}
 
 		case tag.block:
-			if field.v.Kind() == reflect.Slice {
+			if t := blockStructType(field.v.Type()); opt.schema && opt.types != nil && opt.types.recursive[t] {
+				block, err := opt.types.reference(t, tag, field.v.Kind() == reflect.Slice, opt)
+				if err != nil {
+					return nil, nil, err
+				}
+				entries = append(entries, block)
+			} else if field.v.Kind() == reflect.Slice {
 				var blocks []*Block
 				if opt.schema {
 					block, err := sliceToBlockSchema(field.v.Type(), tag, opt)
@@ -275,6 +418,7 @@ func fieldToAttr(field field, tag tag, opt *marshalState) (*Attribute, error) {
 	}
 	var err error
 	if opt.schema {
+		attr.Aliases, attr.Deprecated = tag.aliases, tag.deprecated
 		attr.Value, err = attrSchema(field.v.Type())
 	} else if !(field.v.Kind() == reflect.Ptr && field.v.IsNil()) {
 		attr.Value, err = valueToValue(field.v, opt)
@@ -544,7 +688,45 @@ func valueToBlock(v reflect.Value, tag tag, opt *marshalState) (*Block, error) {
 	}
 	var err error
 	block.Body, block.Labels, err = structToEntries(v, opt)
-	return block, err
+	if err != nil {
+		return nil, err
+	}
+	if opt.schema {
+		block.Comments = append(block.Comments, labelComments(v.Type(), opt)...)
+		block.Aliases, block.Deprecated = tag.aliases, tag.deprecated
+	}
+	return block, nil
+}
+
+// labelComments describes the help:"", enum:"" and pattern:"" tags of the
+// label fields of a struct, for schemas.
+func labelComments(t reflect.Type, opt *marshalState) (lines []string) {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields, err := flattenFields(reflect.New(t).Elem(), opt)
+	if err != nil {
+		return nil
+	}
+	for _, field := range fields {
+		tag := field.tag
+		if !tag.label {
+			continue
+		}
+		if tag.help != "" {
+			lines = append(lines, fmt.Sprintf("Label %s: %s", tag.name, tag.help))
+		}
+		if tag.enum != "" {
+			lines = append(lines, fmt.Sprintf("Label %s must be one of %s.", tag.name, quoteAll(strings.Split(tag.enum, ","))))
+		}
+		if tag.pattern != "" {
+			lines = append(lines, fmt.Sprintf("Label %s must match `%s`.", tag.name, tag.pattern))
+		}
+	}
+	return lines
 }
 
 func sliceToBlocks(sv reflect.Value, tag tag, opt *marshalState) ([]*Block, error) {
@@ -559,26 +741,39 @@ func sliceToBlocks(sv reflect.Value, tag tag, opt *marshalState) ([]*Block, erro
 	return blocks, nil
 }
 
-func marshalNode(w io.Writer, indent string, node Node) error {
+func marshalNode(w io.Writer, indent string, node Node, opt *marshalState) error {
 	switch node := node.(type) {
 	case *AST:
-		return marshalAST(w, indent, node)
+		return marshalAST(w, indent, node, opt)
 	case *Block:
-		return marshalBlock(w, indent, node)
+		return marshalBlock(w, indent, node, opt)
 	case *Attribute:
-		return marshalAttribute(w, indent, node)
+		return marshalAttribute(w, indent, node, opt)
 	case *Comment:
-		marshalComments(w, indent, node.Comments)
+		marshalComments(w, indent, node.Comments, opt)
+		return nil
+	case *MapEntry:
+		marshalComments(w, indent, node.Comments, opt)
+		prefix := fmt.Sprintf("%s%s: ", indent, formatValue(node.Key, opt))
+		fmt.Fprint(w, prefix)
+		if err := marshalValue(w, indent, len(prefix), node.Value, opt); err != nil {
+			return err
+		}
+		marshalLineComment(w, node.LineComment, opt)
+		fmt.Fprintln(w)
 		return nil
 	case Value:
-		return marshalValue(w, indent, node)
+		return marshalValue(w, indent, len(indent), node, opt)
 	default:
 		return fmt.Errorf("can't marshal node of type %T", node)
 	}
 }
 
-func marshalAST(w io.Writer, indent string, ast *AST) error {
-	err := marshalEntries(w, indent, ast.Entries)
+func marshalAST(w io.Writer, indent string, ast *AST, opt *marshalState) error {
+	if ast.source != nil && indent == "" {
+		return ast.source.marshal(w, ast, opt)
+	}
+	err := marshalEntries(w, indent, ast.Entries, opt)
 	if err != nil {
 		return err
 	}
@@ -586,42 +781,31 @@ func marshalAST(w io.Writer, indent string, ast *AST) error {
 	if len(ast.TrailingComments) > 0 {
 		fmt.Fprintln(w)
 	}
-	marshalComments(w, indent, ast.TrailingComments)
+	marshalComments(w, indent, ast.TrailingComments, opt)
 	return nil
 }
 
-func marshalEntries(w io.Writer, indent string, entries []Entry) error {
-	prevAttr := false
+func marshalEntries(w io.Writer, indent string, entries []Entry, opt *marshalState) error {
 	for i, entry := range entries {
+		if i > 0 && opt.blankLine(entries[i-1], entry) {
+			fmt.Fprintln(w)
+		}
 		switch entry := entry.(type) {
 		case *Block:
-			if i > 0 {
-				fmt.Fprintln(w)
-			}
-			if err := marshalBlock(w, indent, entry); err != nil {
+			if err := marshalBlock(w, indent, entry, opt); err != nil {
 				return err
 			}
-			prevAttr = false
 
 		case *Attribute:
-			if i > 0 && !prevAttr {
-				fmt.Fprintln(w)
-			}
-			if err := marshalAttribute(w, indent, entry); err != nil {
+			if err := marshalAttribute(w, indent, entry, opt); err != nil {
 				return err
 			}
-			prevAttr = true
 
 		case *Comment:
-			if i > 0 {
-				fmt.Fprintln(w)
-			}
-			marshalComments(w, indent, entry.Comments)
-			prevAttr = false
+			marshalComments(w, indent, entry.Comments, opt)
 
 		case *RecursiveEntry:
-			fmt.Fprintf(w, "%s// (recursive)\n", indent)
-			prevAttr = false
+			fmt.Fprintf(w, "%s%s (recursive)\n", indent, opt.commentMarker)
 
 		default:
 			panic("??")
@@ -630,11 +814,32 @@ func marshalEntries(w io.Writer, indent string, entries []Entry) error {
 	return nil
 }
 
-func marshalAttribute(w io.Writer, indent string, attribute *Attribute) error {
-	marshalComments(w, indent, attribute.Comments)
-	fmt.Fprintf(w, "%s%s = ", indent, attribute.Key)
+// blankLine returns true if a blank line should separate entry from the
+// preceding entry prev.
+func (m *marshalState) blankLine(prev, entry Entry) bool {
+	switch entry.(type) {
+	case *RecursiveEntry:
+		return false
+	case *Comment:
+		return true
+	}
+	switch m.blankLines {
+	case NoBlankLines:
+		return false
+	case BlankLinesBetweenEntries:
+		return true
+	}
+	_, isAttr := entry.(*Attribute)
+	_, prevIsAttr := prev.(*Attribute)
+	return !isAttr || !prevIsAttr
+}
+
+func marshalAttribute(w io.Writer, indent string, attribute *Attribute, opt *marshalState) error {
+	marshalComments(w, indent, attribute.Comments, opt)
+	prefix := fmt.Sprintf("%s%s = ", indent, attribute.Key)
+	fmt.Fprint(w, prefix)
 	vw := &strings.Builder{}
-	err := marshalValue(vw, indent, attribute.Value)
+	err := marshalValue(vw, indent, len(prefix), attribute.Value, opt)
 	if err != nil {
 		return err
 	}
@@ -644,24 +849,50 @@ func marshalAttribute(w io.Writer, indent string, attribute *Attribute) error {
 			constraints = append(constraints, "optional")
 		}
 		if attribute.Default != nil {
-			constraints = append(constraints, fmt.Sprintf("default(%s)", attribute.Default))
+			dw := &strings.Builder{}
+			column := len(prefix) + len(lastLine(vw.String())) + len("(") + len(strings.Join(append(constraints, "default("), " "))
+			if err := marshalValue(dw, indent, column, attribute.Default, opt); err != nil {
+				return err
+			}
+			constraints = append(constraints, fmt.Sprintf("default(%s)", dw))
 		}
 		if len(attribute.Enum) > 0 {
 			enum := []string{}
 			for _, v := range attribute.Enum {
-				enum = append(enum, v.String())
+				enum = append(enum, formatValue(v, opt))
 			}
 			constraints = append(constraints, fmt.Sprintf("enum(%s)", strings.Join(enum, ", ")))
 		}
+		constraints = append(constraints, aliasConstraints(attribute.Aliases, attribute.Deprecated)...)
 	}
 	fmt.Fprint(w, vw)
 	if len(constraints) > 0 {
 		fmt.Fprintf(w, "(%s)", strings.Join(constraints, " "))
 	}
+	marshalLineComment(w, attribute.LineComment, opt)
 	fmt.Fprintln(w)
 	return nil
 }
 
+// aliasConstraints formats the aliases and deprecation of a schema entry.
+func aliasConstraints(aliases []string, deprecated string) (constraints []string) {
+	if len(aliases) > 0 {
+		quoted := make([]string, len(aliases))
+		for i, alias := range aliases {
+			quoted[i] = strconv.Quote(alias)
+		}
+		constraints = append(constraints, fmt.Sprintf("aliases(%s)", strings.Join(quoted, ", ")))
+	}
+	if deprecated != "" {
+		constraints = append(constraints, fmt.Sprintf("deprecated(%s)", strconv.Quote(deprecated)))
+	}
+	return constraints
+}
+
+func lastLine(s string) string {
+	return s[strings.LastIndex(s, "\n")+1:]
+}
+
 func isType(value Value) bool {
 	switch value := value.(type) {
 	case *Type:
@@ -678,46 +909,188 @@ func isType(value Value) bool {
 	}
 }
 
-func marshalValue(w io.Writer, indent string, value Value) error {
-	if value, ok := value.(*Map); ok {
-		return marshalMap(w, indent+"  ", value.Entries)
+// marshalValue writes value, where indent is the indentation of the line the
+// value starts on and column is the width of the line preceding the value.
+func marshalValue(w io.Writer, indent string, column int, value Value, opt *marshalState) error {
+	if !multiLine(value, column, opt) {
+		fmt.Fprint(w, formatValue(value, opt))
+		return nil
+	}
+	switch value := value.(type) {
+	case *Map:
+		return marshalMap(w, indent, value, opt)
+	case *List:
+		return marshalList(w, indent, value, opt)
 	}
 	fmt.Fprintf(w, "%s", value)
 	return nil
 }
 
-func marshalMap(w io.Writer, indent string, entries []*MapEntry) error {
+// multiLine returns true if a List or Map should be marshalled with one
+// element per line.
+//
+// Values with comments are always multi-line. Otherwise the layout is, in
+// order of precedence, the layout of the original source if PreserveLayout is
+// set, multi-line if OneElementPerLine is set, or multi-line if the value
+// would exceed MaxLineWidth. By default lists are single-line and maps are
+// multi-line.
+func multiLine(value Value, column int, opt *marshalState) bool {
+	var (
+		pos, end lexer.Position
+		empty    bool
+		multi    bool
+	)
+	switch value := value.(type) {
+	case *List:
+		pos, end = value.Pos, value.EndPos
+		empty = len(value.List) == 0 && len(value.TrailingComments) == 0
+	case *Map:
+		pos, end = value.Pos, value.EndPos
+		multi = true
+	default:
+		return false
+	}
+	switch {
+	case empty:
+		return false
+	case hasValueComments(value):
+		return true
+	case opt.preserveLayout && pos.Line > 0 && end.Line > 0:
+		return end.Line > pos.Line
+	case opt.oneElementPerLine:
+		return true
+	case opt.maxLineWidth > 0:
+		return column+len(formatValue(value, opt)) > opt.maxLineWidth
+	}
+	return multi
+}
+
+// formatValue formats value on a single line.
+func formatValue(value Value, opt *marshalState) string {
+	switch value := value.(type) {
+	case *String:
+		return opt.quote(value.Str)
+
+	case *List:
+		elements := make([]string, len(value.List))
+		for i, element := range value.List {
+			elements[i] = formatValue(element, opt)
+		}
+		return "[" + strings.Join(elements, ", ") + "]"
+
+	case *Map:
+		entries := make([]string, len(value.Entries))
+		for i, entry := range value.Entries {
+			entries[i] = formatValue(entry.Key, opt) + ": " + formatValue(entry.Value, opt)
+		}
+		return "{" + strings.Join(entries, ", ") + "}"
+
+	default:
+		return value.String()
+	}
+}
+
+// quote s with single quotes if SingleQuotes is set and s contains no single
+// quotes, or with double quotes otherwise.
+func (m *marshalState) quote(s string) string {
+	quoted := strconv.Quote(s)
+	if !m.singleQuotes || strings.ContainsRune(s, '\'') {
+		return quoted
+	}
+	return "'" + strings.ReplaceAll(quoted[1:len(quoted)-1], `\"`, `"`) + "'"
+}
+
+// label formats a block label, quoting it if necessary or QuoteLabels is set.
+func (m *marshalState) label(label string) string {
+	if m.quoteLabels || needsQuote.MatchString(label) {
+		return m.quote(label)
+	}
+	return label
+}
+
+// hasValueComments returns true if value or any value nested in it has comments.
+func hasValueComments(value Value) bool {
+	switch value := value.(type) {
+	case *List:
+		if value.hasComments() {
+			return true
+		}
+		for _, element := range value.List {
+			if hasValueComments(element) {
+				return true
+			}
+		}
+	case *Map:
+		for _, entry := range value.Entries {
+			if len(entry.Comments) > 0 || entry.LineComment != "" || hasValueComments(entry.Value) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// marshalList writes a list with one element per line.
+func marshalList(w io.Writer, indent string, list *List, opt *marshalState) error {
+	fmt.Fprintln(w, "[")
+	for _, value := range list.List {
+		element := list.element(value)
+		if element != nil {
+			marshalComments(w, indent+opt.indent, element.Comments, opt)
+		}
+		fmt.Fprint(w, indent+opt.indent)
+		if err := marshalValue(w, indent+opt.indent, len(indent+opt.indent), value, opt); err != nil {
+			return err
+		}
+		fmt.Fprint(w, ",")
+		if element != nil {
+			marshalLineComment(w, element.LineComment, opt)
+		}
+		fmt.Fprintln(w)
+	}
+	marshalComments(w, indent+opt.indent, list.TrailingComments, opt)
+	fmt.Fprintf(w, "%s]", indent)
+	return nil
+}
+
+// marshalMap writes a map with one entry per line.
+func marshalMap(w io.Writer, indent string, m *Map, opt *marshalState) error {
 	fmt.Fprintln(w, "{")
-	for _, entry := range entries {
-		marshalComments(w, indent, entry.Comments)
-		fmt.Fprintf(w, "%s%s: ", indent, entry.Key)
-		if err := marshalValue(w, indent+"  ", entry.Value); err != nil {
+	for _, entry := range m.Entries {
+		marshalComments(w, indent+opt.indent, entry.Comments, opt)
+		prefix := fmt.Sprintf("%s%s%s: ", indent, opt.indent, formatValue(entry.Key, opt))
+		fmt.Fprint(w, prefix)
+		if err := marshalValue(w, indent+opt.indent, len(prefix), entry.Value, opt); err != nil {
 			return err
 		}
-		fmt.Fprintln(w, ",")
+		fmt.Fprint(w, ",")
+		marshalLineComment(w, entry.LineComment, opt)
+		fmt.Fprintln(w)
 	}
-	fmt.Fprintf(w, "%s}", indent[:len(indent)-2])
+	fmt.Fprintf(w, "%s}", indent)
 	return nil
 }
 
 var needsQuote = regexp.MustCompile(`[^\w-]`)
 
-func marshalBlock(w io.Writer, indent string, block *Block) error {
-	marshalComments(w, indent, block.Comments)
+func marshalBlock(w io.Writer, indent string, block *Block, opt *marshalState) error {
+	marshalComments(w, indent, block.Comments, opt)
 	prefix := fmt.Sprintf("%s%s", indent, block.Name)
 	fmt.Fprint(w, prefix)
+	constraints := []string{}
 	if block.Repeated {
-		fmt.Fprint(w, "(repeated)")
+		constraints = append(constraints, "repeated")
+	}
+	constraints = append(constraints, aliasConstraints(block.Aliases, block.Deprecated)...)
+	if len(constraints) > 0 {
+		fmt.Fprintf(w, "(%s)", strings.Join(constraints, " "))
 	}
 	labelIndent := len(prefix)
 	size := labelIndent
 	for i, label := range block.Labels {
-		text := label
-		if needsQuote.MatchString(label) {
-			text = strconv.Quote(label)
-		}
+		text := opt.label(label)
 		size += len(text)
-		if i > 0 && size+2 >= 80 {
+		if i > 0 && opt.labelWrapWidth > 0 && size+2 >= opt.labelWrapWidth {
 			size = labelIndent
 			fmt.Fprintf(w, "\n %s", strings.Repeat(" ", labelIndent))
 		} else {
@@ -726,14 +1099,25 @@ func marshalBlock(w io.Writer, indent string, block *Block) error {
 		fmt.Fprintf(w, "%s", text)
 	}
 
+	if block.Ref != "" {
+		fmt.Fprintf(w, " = type(%s)", block.Ref)
+		marshalLineComment(w, block.EndComment, opt)
+		fmt.Fprintln(w)
+		return nil
+	}
+
 	// Check if block is empty and has no trailing comments
-	if len(block.Body) == 0 && len(block.TrailingComments) == 0 {
-		fmt.Fprintln(w, " {}")
+	if len(block.Body) == 0 && len(block.TrailingComments) == 0 && (block.LineComment == "" || block.EndComment == "") {
+		fmt.Fprint(w, " {}")
+		marshalLineComment(w, block.LineComment+block.EndComment, opt)
+		fmt.Fprintln(w)
 		return nil
 	}
 
-	fmt.Fprintln(w, " {")
-	err := marshalEntries(w, indent+"  ", block.Body)
+	fmt.Fprint(w, " {")
+	marshalLineComment(w, block.LineComment, opt)
+	fmt.Fprintln(w)
+	err := marshalEntries(w, indent+opt.indent, block.Body, opt)
 	if err != nil {
 		return err
 	}
@@ -743,17 +1127,26 @@ func marshalBlock(w io.Writer, indent string, block *Block) error {
 		if len(block.Body) > 0 {
 			fmt.Fprintln(w)
 		}
-		marshalComments(w, indent+"  ", block.TrailingComments)
+		marshalComments(w, indent+opt.indent, block.TrailingComments, opt)
 	}
 
-	fmt.Fprintf(w, "%s}\n", indent)
+	fmt.Fprintf(w, "%s}", indent)
+	marshalLineComment(w, block.EndComment, opt)
+	fmt.Fprintln(w)
 	return nil
 }
 
-func marshalComments(w io.Writer, indent string, comments []string) {
+// marshalLineComment writes a comment on the same line as the preceding output.
+func marshalLineComment(w io.Writer, comment string, opt *marshalState) {
+	if comment != "" {
+		fmt.Fprintf(w, " %s %s", opt.commentMarker, comment)
+	}
+}
+
+func marshalComments(w io.Writer, indent string, comments []string, opt *marshalState) {
 	for _, comment := range comments {
 		for _, line := range strings.Split(comment, "\n") {
-			fmt.Fprintf(w, "%s// %s\n", indent, line)
+			fmt.Fprintf(w, "%s%s %s\n", indent, opt.commentMarker, line)
 		}
 	}
 }
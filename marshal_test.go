@@ -93,7 +93,7 @@ func TestMarshal(t *testing.T) {
 				Delay time.Duration `hcl:"delay,optional" default:"24h"`
 			}{},
 			expected: `
-delay = string(optional default("24h"))
+delay = duration(optional default("24h"))
 `,
 			options: []MarshalOption{asSchema()},
 		},
@@ -102,7 +102,7 @@ delay = string(optional default("24h"))
 				Delay *time.Duration `hcl:"delay,optional" default:"24h"`
 			}{},
 			expected: `
-delay = string(optional default("24h"))
+delay = duration(optional default("24h"))
 `,
 			options: []MarshalOption{asSchema()},
 		},
@@ -466,6 +466,242 @@ func TestOptionalDefaultOmitted(t *testing.T) {
 	assert.Equal(t, "", string(data))
 }
 
+func TestMarshalLineComments(t *testing.T) {
+	source := `port = 80 // HTTP
+hosts = [
+  "a", // first
+  "b",
+]
+labels = {
+  "env": "prod", // environment
+}
+
+service web { // The web service.
+  enabled = true
+}
+
+service db {} // The database.
+`
+	ast, err := ParseString(source)
+	assert.NoError(t, err)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, source, string(data))
+
+	clone, err := MarshalAST(ast.Clone())
+	assert.NoError(t, err)
+	assert.Equal(t, source, string(clone))
+}
+
+func TestMarshalBlockEndComments(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"End", "block {\n  a = 1\n} // close\n"},
+		{"OpenAndEnd", "block { // open\n  a = 1\n} // close\n"},
+		{"EmptyEnd", "block {} // close\n"},
+		{"EmptyOpenAndEnd", "block { // open\n} // close\n"},
+		{"Nested", "outer {\n  inner {\n    a = 1\n  } // close\n\n  b = 2\n} // outer\n"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ast, err := ParseString(test.source)
+			assert.NoError(t, err)
+			data, err := MarshalAST(ast)
+			assert.NoError(t, err)
+			assert.Equal(t, test.source, string(data))
+			data, err = MarshalAST(ast.Clone())
+			assert.NoError(t, err)
+			assert.Equal(t, test.source, string(data))
+		})
+	}
+}
+
+func TestMarshalBlockComment(t *testing.T) {
+	ast, err := ParseString("a = [1, /* x */ 2]\n")
+	assert.NoError(t, err)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, "a = [\n  1, // x\n  2,\n]\n", string(data))
+}
+
+func TestMarshalListComments(t *testing.T) {
+	ast, err := ParseString(`
+allow = [
+	// Internal.
+	"10.0.0.1", "api.example.com", // api
+	// Disabled.
+]
+deny = ["a", "b"]
+`)
+	assert.NoError(t, err)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `allow = [
+  // Internal.
+  "10.0.0.1",
+  "api.example.com", // api
+  // Disabled.
+]
+deny = ["a", "b"]
+`, string(data))
+
+	// Comments follow their elements when the list is modified.
+	list := ast.Entries[0].(*Attribute).Value.(*List)
+	list.List = append(list.List[1:], &String{Str: "new"})
+	list.TrailingComments = nil
+	data, err = MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `allow = [
+  "api.example.com", // api
+  "new",
+]
+deny = ["a", "b"]
+`, string(data))
+}
+
+func TestMarshalLayout(t *testing.T) {
+	const source = `list = ["a", "b"]
+long = [
+  "a",
+]
+map = {"a": 1, "b": {"c": 2}}
+`
+	tests := []struct {
+		name     string
+		options  []MarshalOption
+		expected string
+	}{
+		{name: "Default",
+			expected: `list = ["a", "b"]
+long = ["a"]
+map = {
+  "a": 1,
+  "b": {
+    "c": 2,
+  },
+}
+`},
+		{name: "OneElementPerLine",
+			options: []MarshalOption{OneElementPerLine(true)},
+			expected: `list = [
+  "a",
+  "b",
+]
+long = [
+  "a",
+]
+map = {
+  "a": 1,
+  "b": {
+    "c": 2,
+  },
+}
+`},
+		{name: "MaxLineWidth",
+			options: []MarshalOption{MaxLineWidth(20)},
+			expected: `list = ["a", "b"]
+long = ["a"]
+map = {
+  "a": 1,
+  "b": {"c": 2},
+}
+`},
+		{name: "PreserveLayout",
+			options: []MarshalOption{PreserveLayout(true), OneElementPerLine(true)},
+			expected: `list = ["a", "b"]
+long = [
+  "a",
+]
+map = {"a": 1, "b": {"c": 2}}
+`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ast, err := ParseString(source)
+			assert.NoError(t, err)
+			data, err := MarshalAST(ast, test.options...)
+			assert.NoError(t, err)
+			assert.Equal(t, test.expected, string(data))
+		})
+	}
+}
+
+func TestMarshalLayoutDefault(t *testing.T) {
+	ast, err := ParseString(`
+ports = [number](optional default([80, 443, 8080]))
+`)
+	assert.NoError(t, err)
+	data, err := MarshalAST(ast, MaxLineWidth(40))
+	assert.NoError(t, err)
+	assert.Equal(t, `ports = [number](optional default([
+  80,
+  443,
+  8080,
+]))
+`, string(data))
+}
+
+func TestMarshalFormatOptions(t *testing.T) {
+	ast, err := ParseString(`
+// Comment.
+name = "it's"
+version = "1.0"
+service "web" "api.example.com" {
+  port = 80 // HTTP
+  hosts = ["a", "b"]
+}
+`)
+	assert.NoError(t, err)
+	w := &strings.Builder{}
+	err = MarshalASTToWriter(ast, w,
+		Indent("\t"),
+		CommentMarker("#"),
+		WithBlankLines(BlankLinesBetweenEntries),
+		QuoteLabels(true),
+		SingleQuotes(true),
+		OneElementPerLine(true),
+	)
+	assert.NoError(t, err)
+	assert.Equal(t, `# Comment.
+name = "it's"
+
+version = '1.0'
+
+service 'web' 'api.example.com' {
+	port = 80 # HTTP
+
+	hosts = [
+		'a',
+		'b',
+	]
+}
+`, w.String())
+
+	w.Reset()
+	err = MarshalASTWithOptions(ast, w, WithBlankLines(NoBlankLines))
+	assert.NoError(t, err)
+	assert.Equal(t, `// Comment.
+name = "it's"
+version = "1.0"
+service web "api.example.com" {
+  port = 80 // HTTP
+  hosts = ["a", "b"]
+}
+`, w.String())
+}
+
+func TestMarshalLabelWrapWidth(t *testing.T) {
+	ast := &AST{Entries: []Entry{&Block{Name: "block", Labels: []string{"first", "second", "third"}}}}
+	data, err := MarshalAST(ast, LabelWrapWidth(20))
+	assert.NoError(t, err)
+	assert.Equal(t, "block first second\n      third {}\n", string(data))
+	data, err = MarshalAST(ast, LabelWrapWidth(0))
+	assert.NoError(t, err)
+	assert.Equal(t, "block first second third {}\n", string(data))
+}
+
 func TestMarshalAST(t *testing.T) {
 	tests := []struct {
 		name     string
@@ -0,0 +1,214 @@
+package hcl
+
+import (
+	"os"
+	"reflect"
+
+	"github.com/alecthomas/participle/v2"
+)
+
+// Values of the merge:"" struct tag.
+const (
+	mergeAppend  = "append"
+	mergeReplace = "replace"
+)
+
+// MergeOption configures MergeAST.
+type MergeOption func(options *mergeState)
+
+type mergeState struct {
+	conflictErrors bool
+	appendLists    bool
+	typ            reflect.Type
+}
+
+// ErrorOnConflict returns an error if an attribute is set by more than one
+// AST, reporting the positions of both definitions.
+func ErrorOnConflict(v bool) MergeOption {
+	return func(options *mergeState) {
+		options.conflictErrors = v
+	}
+}
+
+// AppendLists appends list attributes set in an overlay to those in the base,
+// rather than replacing them.
+//
+// This can be overridden per field with a merge:"append" or merge:"replace"
+// struct tag if the type is provided with MergeType.
+func AppendLists(v bool) MergeOption {
+	return func(options *mergeState) {
+		options.appendLists = v
+	}
+}
+
+// MergeType provides the Go type being merged, which must be a pointer to a
+// struct, so that merge:"" struct tags can be applied.
+func MergeType(v interface{}) MergeOption {
+	return func(options *mergeState) {
+		t := reflect.TypeOf(v)
+		for t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		options.typ = t
+	}
+}
+
+// MergeAST layers overlays on top of base, returning a new AST. The inputs are
+// not modified.
+//
+// The rules are applied in order of the overlays:
+//
+//   - Attributes in an overlay replace attributes of the same name.
+//   - Lists are replaced, or appended to with AppendLists or a merge:"append" tag.
+//   - Blocks are merged with the block of the same name and labels, recursively.
+//     If there is no such block, or more than one, the block is appended.
+//   - Comments of an overlay entry replace those of the entry it is merged with,
+//     and comments not attached to an entry are discarded.
+func MergeAST(base *AST, overlays []*AST, options ...MergeOption) (*AST, error) {
+	state := &mergeState{}
+	for _, option := range options {
+		option(state)
+	}
+	out := base.Clone()
+	if out == nil {
+		out = &AST{}
+	}
+	for _, overlay := range overlays {
+		if overlay == nil {
+			continue
+		}
+		entries, err := state.mergeEntries(out.Entries, overlay.Clone().Entries, state.typ)
+		if err != nil {
+			return nil, err
+		}
+		out.Entries = entries
+	}
+	return out, AddParentRefs(out)
+}
+
+// UnmarshalFiles parses, merges and unmarshals HCL files into v, in order,
+// with the semantics of MergeAST.
+func UnmarshalFiles(v interface{}, paths ...string) error {
+	asts := make([]*AST, 0, len(paths))
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		ast, err := ParseBytes(data, WithFilename(path))
+		if err != nil {
+			return err
+		}
+		asts = append(asts, ast)
+	}
+	if len(asts) == 0 {
+		return UnmarshalAST(&AST{}, v)
+	}
+	merged, err := MergeAST(asts[0], asts[1:], MergeType(v))
+	if err != nil {
+		return err
+	}
+	return UnmarshalAST(merged, v)
+}
+
+func (m *mergeState) mergeEntries(base, overlay []Entry, t reflect.Type) ([]Entry, error) {
+	fields := mergeFields(t)
+	for _, entry := range overlay {
+		switch entry := entry.(type) {
+		case *Attribute:
+			index := -1
+			for i, existing := range base {
+				if existing, ok := existing.(*Attribute); ok && existing.Key == entry.Key {
+					index = i
+				}
+			}
+			if index == -1 {
+				base = append(base, entry)
+				continue
+			}
+			existing := base[index].(*Attribute)
+			if m.conflictErrors {
+				return nil, participle.Errorf(entry.Pos, "attribute %q conflicts with definition at %s", entry.Key, existing.Pos)
+			}
+			if len(entry.Comments) == 0 {
+				entry.Comments = existing.Comments
+			}
+			appendList := m.appendLists
+			if f, ok := fields[entry.Key]; ok && f.tag.merge != "" {
+				appendList = f.tag.merge == mergeAppend
+			}
+			overlayList, overlayIsList := entry.Value.(*List)
+			baseList, baseIsList := existing.Value.(*List)
+			if appendList && overlayIsList && baseIsList {
+				entry.Value = &List{
+					Pos:      overlayList.Pos,
+					List:     append(baseList.List, overlayList.List...),
+					Elements: append(baseList.Elements, overlayList.Elements...),
+				}
+			}
+			base[index] = entry
+
+		case *Block:
+			var matches []*Block
+			for _, existing := range base {
+				if existing, ok := existing.(*Block); ok && existing.Name == entry.Name && sameLabels(existing.Labels, entry.Labels) {
+					matches = append(matches, existing)
+				}
+			}
+			if len(matches) != 1 {
+				base = append(base, entry)
+				continue
+			}
+			existing := matches[0]
+			var blockType reflect.Type
+			if f, ok := fields[entry.Name]; ok && f.tag.block {
+				blockType = f.t.Type
+			}
+			body, err := m.mergeEntries(existing.Body, entry.Body, blockType)
+			if err != nil {
+				return nil, err
+			}
+			existing.Body = body
+			if len(entry.Comments) > 0 {
+				existing.Comments = entry.Comments
+			}
+			if len(entry.TrailingComments) > 0 {
+				existing.TrailingComments = entry.TrailingComments
+			}
+		}
+	}
+	return base, nil
+}
+
+// mergeFields returns the fields of a struct type, keyed by attribute or block name.
+func mergeFields(t reflect.Type) map[string]field {
+	for t != nil && (t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice) {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return nil
+	}
+	fields, err := flattenFields(reflect.New(t).Elem(), newMarshalState())
+	if err != nil {
+		return nil
+	}
+	out := map[string]field{}
+	for _, f := range fields {
+		if !f.tag.label && f.tag.name != "" {
+			out[f.tag.name] = f
+		}
+	}
+	return out
+}
+
+func sameLabels(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,122 @@
+package hcl
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestMergeAST(t *testing.T) {
+	base, err := ParseString(`
+// The version.
+version = 1
+hosts = ["a"]
+
+server {
+  port = 80
+  tls = false
+}
+
+service "web" {
+  replicas = 1
+}
+`)
+	assert.NoError(t, err)
+	overlay, err := ParseString(`
+version = 2
+hosts = ["b"]
+
+server {
+  port = 443
+}
+
+service "web" {
+  replicas = 3
+}
+
+service "db" {
+  replicas = 1
+}
+`)
+	assert.NoError(t, err)
+	merged, err := MergeAST(base, []*AST{overlay})
+	assert.NoError(t, err)
+	data, err := MarshalAST(merged)
+	assert.NoError(t, err)
+	assert.Equal(t, `// The version.
+version = 2
+hosts = ["b"]
+
+server {
+  port = 443
+  tls = false
+}
+
+service web {
+  replicas = 3
+}
+
+service db {
+  replicas = 1
+}
+`, string(data))
+
+	// The inputs are not modified.
+	assert.Equal(t, "1", base.Entries[0].(*Attribute).Value.String())
+
+	merged, err = MergeAST(base, []*AST{overlay}, AppendLists(true))
+	assert.NoError(t, err)
+	assert.Equal(t, `["a", "b"]`, merged.Entries[1].(*Attribute).Value.String())
+}
+
+func TestMergeASTConflict(t *testing.T) {
+	base, err := ParseString("server {\n  port = 80\n}\n", WithFilename("base.hcl"))
+	assert.NoError(t, err)
+	overlay, err := ParseString("\nserver {\n  port = 443\n}\n", WithFilename("prod.hcl"))
+	assert.NoError(t, err)
+	_, err = MergeAST(base, []*AST{overlay}, ErrorOnConflict(true))
+	assert.EqualError(t, err, `prod.hcl:3:3: attribute "port" conflicts with definition at base.hcl:2:3`)
+}
+
+func TestUnmarshalFiles(t *testing.T) {
+	type server struct {
+		Port  int      `hcl:"port"`
+		Hosts []string `hcl:"hosts" merge:"append"`
+		Tags  []string `hcl:"tags,optional"`
+	}
+	type config struct {
+		Name   string  `hcl:"name"`
+		Server *server `hcl:"server,block"`
+	}
+	dir := t.TempDir()
+	base := filepath.Join(dir, "base.hcl")
+	local := filepath.Join(dir, "local.hcl")
+	assert.NoError(t, os.WriteFile(base, []byte(`
+name = "base"
+server {
+  port = 80
+  hosts = ["a"]
+  tags = ["x"]
+}
+`), 0600))
+	assert.NoError(t, os.WriteFile(local, []byte(`
+server {
+  port = 8080
+  hosts = ["b"]
+  tags = ["y"]
+}
+`), 0600))
+	actual := &config{}
+	err := UnmarshalFiles(actual, base, local)
+	assert.NoError(t, err)
+	assert.Equal(t, &config{
+		Name: "base",
+		Server: &server{
+			Port:  8080,
+			Hosts: []string{"a", "b"},
+			Tags:  []string{"y"},
+		},
+	}, actual)
+}
@@ -0,0 +1,124 @@
+package hcl
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+
+	"github.com/alecthomas/participle/v2"
+)
+
+// Migration upgrades a configuration AST by one version, eg. by moving or
+// restructuring entries with Get, Set and Delete.
+type Migration func(ast *AST) error
+
+// Migrations upgrade configuration documents to the latest version.
+//
+// Documents declare their version with a top-level "version = N" attribute,
+// and documents without one are at version 0. Configuration structs should
+// include a "version" field to receive it.
+//
+// The zero value has no migrations and is ready to use.
+type Migrations struct {
+	steps map[int]Migration
+}
+
+// Register the migration upgrading documents from version-1 to version.
+//
+// Registering a version less than 1, or the same version twice, panics.
+func (m *Migrations) Register(version int, migration Migration) *Migrations {
+	if version < 1 {
+		panic(fmt.Sprintf("migration version must be at least 1, not %d", version))
+	}
+	if _, ok := m.steps[version]; ok {
+		panic(fmt.Sprintf("migration to version %d registered twice", version))
+	}
+	if m.steps == nil {
+		m.steps = map[int]Migration{}
+	}
+	m.steps[version] = migration
+	return m
+}
+
+// Latest returns the highest registered version, which documents are migrated to.
+func (m *Migrations) Latest() int {
+	latest := 0
+	for version := range m.steps {
+		if version > latest {
+			latest = version
+		}
+	}
+	return latest
+}
+
+// Migrate ast in place to the latest version, applying the migrations for
+// each version after the one it declares in order, and updating its version
+// attribute, which is added before the first entry if absent.
+//
+// It returns true if any migrations were applied.
+func (m *Migrations) Migrate(ast *AST) (bool, error) {
+	attr, version, err := documentVersion(ast)
+	if err != nil {
+		return false, err
+	}
+	latest := m.Latest()
+	if version > latest {
+		return false, participle.Errorf(attr.Pos, "version %d is newer than the latest supported version %d", version, latest)
+	}
+	if version == latest {
+		return false, nil
+	}
+	versions := make([]int, 0, len(m.steps))
+	for v := range m.steps {
+		if v > version {
+			versions = append(versions, v)
+		}
+	}
+	sort.Ints(versions)
+	for i, v := range versions {
+		if v != version+i+1 {
+			return false, fmt.Errorf("no migration to version %d", version+i+1)
+		}
+	}
+	for _, v := range versions {
+		if err := m.steps[v](ast); err != nil {
+			return false, fmt.Errorf("migration to version %d failed: %w", v, err)
+		}
+	}
+	value := &Number{Float: big.NewFloat(float64(latest))}
+	// The migrations may have replaced the version attribute.
+	if attr, _, _ = documentVersion(ast); attr != nil {
+		attr.Value = value
+		addParentRefs(attr, value)
+	} else {
+		attr = &Attribute{Key: "version", Value: value}
+		ast.Entries = append(Entries{attr}, ast.Entries...)
+		addParentRefs(ast, attr)
+	}
+	return true, nil
+}
+
+// WithMigrations migrates a copy of the AST before unmarshalling it.
+func WithMigrations(migrations *Migrations) MarshalOption {
+	return func(options *marshalState) {
+		options.migrations = migrations
+	}
+}
+
+// documentVersion returns the top-level version attribute of ast, if any, and
+// the version it declares.
+func documentVersion(ast *AST) (*Attribute, int, error) {
+	for _, entry := range ast.Entries {
+		attr, ok := entry.(*Attribute)
+		if !ok || attr.Key != "version" {
+			continue
+		}
+		number, ok := attr.Value.(*Number)
+		if !ok || !number.Float.IsInt() || number.Float.Sign() < 0 {
+			return nil, 0, participle.Errorf(attr.Pos, "version must be a non-negative integer, not %s", schemaString(attr.Value))
+		}
+		version, _ := number.Float.Int64()
+		return attr, int(version), nil
+	}
+	return nil, 0, nil
+}
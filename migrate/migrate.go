@@ -0,0 +1,137 @@
+// Package migrate implements a command that upgrades configuration files in
+// place with an application's migrations.
+//
+// Applications build the command with their own migrations:
+//
+//	func main() {
+//		migrations := &hcl.Migrations{}
+//		migrations.Register(1, moveListenAddr)
+//		migrate.Main("myapp-migrate", migrations)
+//	}
+//
+// Usage:
+//
+//	myapp-migrate [flags] file...
+//
+// Files are parsed losslessly, so comments and the formatting of entries not
+// touched by migrations are preserved. With -check, files are not modified and
+// the names of files needing migration are printed.
+//
+// The exit code is 0 on success, 1 if a file could not be migrated or, with
+// -check, if any file needs migration, and 2 on invalid usage.
+package migrate
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+// Bytes migrates a configuration document, returning the migrated document
+// and true if any migrations were applied.
+//
+// filename is used in error positions.
+func Bytes(data []byte, filename string, migrations *hcl.Migrations) ([]byte, bool, error) {
+	ast, err := hcl.ParseBytes(data, hcl.WithFilename(filename), hcl.WithLossless(true))
+	if err != nil {
+		return nil, false, err
+	}
+	changed, err := migrations.Migrate(ast)
+	if err != nil || !changed {
+		return data, false, err
+	}
+	data, err = hcl.MarshalAST(ast)
+	if err != nil {
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// File migrates the configuration file at path in place, returning true if it
+// was changed.
+//
+// The migrated file is written to a temporary file in the same directory,
+// which then replaces the original, so a failed write leaves it intact.
+func File(path string, migrations *hcl.Migrations) (bool, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return false, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return false, err
+	}
+	data, changed, err := Bytes(data, path, migrations)
+	if err != nil || !changed {
+		return false, err
+	}
+	return true, replaceFile(path, data, info.Mode().Perm())
+}
+
+func replaceFile(path string, data []byte, perm os.FileMode) error {
+	f, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // nolint: errcheck
+	if _, err := f.Write(data); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// Main runs the command named name with os.Args, and exits.
+func Main(name string, migrations *hcl.Migrations) {
+	flags := flag.NewFlagSet(name, flag.ExitOnError)
+	check := flags.Bool("check", false, "don't modify files, and exit 1 if any need migration")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "usage: %s [flags] file...\n\nMigrates configuration files to version %d in place.\n\n", name, migrations.Latest())
+		flags.PrintDefaults()
+	}
+	_ = flags.Parse(os.Args[1:])
+	if flags.NArg() == 0 {
+		flags.Usage()
+		os.Exit(2)
+	}
+	if err := run(flags.Args(), migrations, *check); err != nil {
+		fmt.Fprintf(os.Stderr, "%s: %s\n", name, err)
+		os.Exit(1)
+	}
+}
+
+func run(paths []string, migrations *hcl.Migrations, check bool) error {
+	outdated := 0
+	for _, path := range paths {
+		if !check {
+			if _, err := File(path, migrations); err != nil {
+				return err
+			}
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		if _, changed, err := Bytes(data, path, migrations); err != nil {
+			return err
+		} else if changed {
+			fmt.Println(path)
+			outdated++
+		}
+	}
+	if outdated > 0 {
+		return fmt.Errorf("%d file(s) need migration", outdated)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package migrate
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+
+	"github.com/alecthomas/hcl/v2"
+)
+
+func testMigrations() *hcl.Migrations {
+	migrations := &hcl.Migrations{}
+	migrations.Register(1, func(ast *hcl.AST) error {
+		node, err := hcl.Get(ast, "listen_addr")
+		if err != nil {
+			return err
+		}
+		if err := hcl.Delete(ast, "listen_addr"); err != nil {
+			return err
+		}
+		return hcl.Set(ast, "server.bind", node.(*hcl.Attribute).Value)
+	})
+	return migrations
+}
+
+func TestFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.hcl")
+	err := os.WriteFile(path, []byte(`// Header comment.
+
+// Address to listen on.
+listen_addr = ':80'
+
+/* Logging. */
+log {
+  level   = "info" # or "debug"
+}
+`), 0640)
+	assert.NoError(t, err)
+	changed, err := File(path, testMigrations())
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Equal(t, `// Header comment.
+
+version = 1
+
+/* Logging. */
+log {
+  level   = "info" # or "debug"
+}
+
+server {
+  bind = ":80"
+}
+`, string(data))
+	info, err := os.Stat(path)
+	assert.NoError(t, err)
+	assert.Equal(t, os.FileMode(0640), info.Mode().Perm())
+	entries, err := os.ReadDir(filepath.Dir(path))
+	assert.NoError(t, err)
+	assert.Equal(t, 1, len(entries))
+
+	changed, err = File(path, testMigrations())
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestBytesError(t *testing.T) {
+	_, _, err := Bytes([]byte("version = 2\n"), "config.hcl", testMigrations())
+	assert.EqualError(t, err, "config.hcl:1:1: version 2 is newer than the latest supported version 1")
+}
@@ -0,0 +1,152 @@
+package hcl
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+type migratedConfig struct {
+	Version int `hcl:"version"`
+	Server  struct {
+		Bind string `hcl:"bind"`
+	} `hcl:"server,block"`
+	Backends []struct {
+		Host string `hcl:"host,label"`
+	} `hcl:"backend,block"`
+}
+
+func testMigrations() *Migrations {
+	migrations := &Migrations{}
+	// Move listen_addr into a server block as bind.
+	migrations.Register(1, func(ast *AST) error {
+		node, err := Get(ast, "listen_addr")
+		if err != nil {
+			return err
+		}
+		if err := Delete(ast, "listen_addr"); err != nil {
+			return err
+		}
+		return Set(ast, "server.bind", node.(*Attribute).Value)
+	})
+	// Split the backends list into repeated backend blocks.
+	migrations.Register(2, func(ast *AST) error {
+		node, err := Get(ast, "backends")
+		if err != nil {
+			return err
+		}
+		list, ok := node.(*Attribute).Value.(*List)
+		if !ok {
+			return fmt.Errorf("%s: backends must be a list", node.Position())
+		}
+		if err := Delete(ast, "backends"); err != nil {
+			return err
+		}
+		for _, value := range list.List {
+			block := &Block{Name: "backend", Labels: []string{value.(*String).Str}}
+			ast.Entries = append(ast.Entries, block)
+		}
+		return AddParentRefs(ast)
+	})
+	return migrations
+}
+
+func TestMigrate(t *testing.T) {
+	ast, err := ParseString(`
+// Address to listen on.
+listen_addr = ":80"
+backends = ["a", "b"]
+`)
+	assert.NoError(t, err)
+	migrations := testMigrations()
+	assert.Equal(t, 2, migrations.Latest())
+	changed, err := migrations.Migrate(ast)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `version = 2
+
+server {
+  bind = ":80"
+}
+
+backend a {}
+
+backend b {}
+`, string(data))
+
+	changed, err = migrations.Migrate(ast)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+}
+
+func TestMigrateFromVersion(t *testing.T) {
+	ast, err := ParseString(`
+version = 1
+server {
+  bind = ":80"
+}
+backends = ["a"]
+`)
+	assert.NoError(t, err)
+	_, err = testMigrations().Migrate(ast)
+	assert.NoError(t, err)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, `version = 2
+
+server {
+  bind = ":80"
+}
+
+backend a {}
+`, string(data))
+}
+
+func TestMigrateErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		source   string
+		register []int
+		err      string
+	}{
+		{"Newer", `version = 3`, []int{1, 2}, `1:1: version 3 is newer than the latest supported version 2`},
+		{"InvalidVersion", `version = "1"`, []int{1}, `1:1: version must be a non-negative integer, not "1"`},
+		{"Gap", `version = 0`, []int{1, 3}, `no migration to version 2`},
+		{"Failed", `version = 0`, []int{1}, `migration to version 1 failed: "listen_addr" not found`},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			ast, err := ParseString(test.source)
+			assert.NoError(t, err)
+			migrations := &Migrations{}
+			for _, version := range test.register {
+				migrations.Register(version, testMigrations().steps[1])
+			}
+			_, err = migrations.Migrate(ast)
+			assert.EqualError(t, err, test.err)
+		})
+	}
+	assert.Panics(t, func() { (&Migrations{}).Register(0, nil) })
+	assert.Panics(t, func() { testMigrations().Register(1, nil) })
+}
+
+func TestUnmarshalWithMigrations(t *testing.T) {
+	ast, err := ParseString(`
+listen_addr = ":80"
+backends = ["a", "b"]
+`)
+	assert.NoError(t, err)
+	var config migratedConfig
+	err = UnmarshalAST(ast, &config, WithMigrations(testMigrations()))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, config.Version)
+	assert.Equal(t, ":80", config.Server.Bind)
+	assert.Equal(t, 2, len(config.Backends))
+	assert.Equal(t, "b", config.Backends[1].Host)
+	// The AST is not modified.
+	_, err = Get(ast, "listen_addr")
+	assert.NoError(t, err)
+}
@@ -7,6 +7,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/fs"
 	"math/big"
 	"regexp"
 	"strconv"
@@ -71,6 +72,9 @@ type AST struct {
 	Entries          Entries `parser:"@@*"`
 	TrailingComments CommentList
 	Schema           bool `parser:""`
+
+	// Original source, retained by WithLossless.
+	source *sourceMap
 }
 
 func (a *AST) Detach() bool { return false }
@@ -113,13 +117,17 @@ type Entry interface {
 }
 
 // RecursiveEntry is an Entry representing that a schema is recursive.
+//
+// Schema represents recursion with type definitions referenced by Block.Ref,
+// but RecursiveEntry is still marshalled and understood by the schema
+// functions of this package.
 type RecursiveEntry struct{}
 
 func (*RecursiveEntry) Position() Position          { return Position{} }
 func (*RecursiveEntry) children() (children []Node) { return nil }
 func (*RecursiveEntry) Clone() Entry                { return &RecursiveEntry{} }
 func (*RecursiveEntry) Detach() bool                { return false }
-func (*RecursiveEntry) EntryKey() string            { panic("unimplemented") }
+func (*RecursiveEntry) EntryKey() string            { return "" }
 
 var _ Entry = &RecursiveEntry{}
 
@@ -133,9 +141,17 @@ type Attribute struct {
 	Key   string `parser:"@Ident"`
 	Value Value  `parser:"( '=':Punct @@ )?"`
 
-	Default  Value   `parser:"( '(' ( (  'default' '(' @@ ')'"`
-	Enum     []Value `parser:"         | 'enum' '(' @@ (',' @@)* ')'"`
-	Optional bool    `parser:"         | @'optional' ) )+ ')' )?"`
+	Default Value   `parser:"( '(' ( (  'default' '(' @@ ')'"`
+	Enum    []Value `parser:"         | 'enum' '(' @@ (',' @@)* ')'"`
+	// Aliases are alternative keys accepted for the attribute in a schema.
+	Aliases []string `parser:"         | 'aliases' '(' @String (',' @String)* ')'"`
+	// Deprecated is the deprecation message of the attribute in a schema, or
+	// of its aliases if it has any.
+	Deprecated string `parser:"         | 'deprecated' '(' @String ')'"`
+	Optional   bool   `parser:"         | @'optional' ) )+ ')' )?"`
+
+	// LineComment is a comment on the same line as the attribute, following its value.
+	LineComment string `parser:"@LineComment?"`
 }
 
 var _ Entry = &Attribute{}
@@ -156,11 +172,14 @@ func (a *Attribute) Clone() Entry {
 		return nil
 	}
 	return &Attribute{
-		Pos:      a.Pos,
-		Comments: cloneStrings(a.Comments),
-		Key:      a.Key,
-		Value:    a.Value.Clone(),
-		Optional: a.Optional,
+		Pos:         a.Pos,
+		Comments:    cloneStrings(a.Comments),
+		Key:         a.Key,
+		Value:       a.Value.Clone(),
+		Aliases:     cloneStrings(a.Aliases),
+		Deprecated:  a.Deprecated,
+		Optional:    a.Optional,
+		LineComment: a.LineComment,
 	}
 }
 
@@ -169,7 +188,7 @@ type Comment struct {
 	EndPos lexer.Position `parser:""`
 	Parent Node           `parser:""`
 
-	Comments CommentList `parser:"@Comment"`
+	Comments CommentList `parser:"@(Comment | LineComment)"`
 }
 
 var _ Entry = &Comment{}
@@ -195,16 +214,34 @@ func (a *Comment) Clone() Entry {
 // Block represents am optionally labelled HCL block.
 type Block struct {
 	Pos    lexer.Position `parser:""`
+	EndPos lexer.Position `parser:""`
 	Parent Node           `parser:""`
 
 	Comments CommentList
 
-	Name     string   `parser:"@Ident"`
-	Repeated bool     `parser:"( '(' @'repeated' ')' )?"`
-	Labels   []string `parser:"@( Ident | String )*"`
-	Body     Entries  `parser:"'{' @@* '}'"`
+	Name     string `parser:"@Ident"`
+	Repeated bool   `parser:"( '(' ( @'repeated'"`
+	// Aliases are alternative names accepted for the block in a schema.
+	Aliases []string `parser:"      | 'aliases' '(' @String (',' @String)* ')'"`
+	// Deprecated is the deprecation message of the block in a schema, or of
+	// its aliases if it has any.
+	Deprecated string   `parser:"      | 'deprecated' '(' @String ')' )+ ')' )?"`
+	Labels     []string `parser:"@( Ident | String )*"`
+	// LineComment is a comment on the same line as the opening brace.
+	LineComment string  `parser:"( '{' @LineComment?"`
+	Body        Entries `parser:"  @@* '}'"`
+	// Ref is the name of the type definition of a recursive block in a
+	// schema, as in `child "name" = type(Node)`, in which case Body is empty.
+	// See Schema.
+	//
+	// References are only parsed as blocks if the document is parsed
+	// WithSchema and defines the type with a top-level `type Node { ... }`
+	// block, and are otherwise attributes with a Type value.
+	Ref string `parser:"| '=' 'type' '(' @Ident ')' )"`
 
 	TrailingComments CommentList
+	// EndComment is a comment on the same line as the closing brace.
+	EndComment string
 }
 
 var _ Entry = &Block{}
@@ -241,6 +278,11 @@ func (b *Block) Clone() Entry {
 		Body:             make(Entries, len(b.Body)),
 		TrailingComments: cloneStrings(b.TrailingComments),
 		Repeated:         b.Repeated,
+		Aliases:          cloneStrings(b.Aliases),
+		Deprecated:       b.Deprecated,
+		LineComment:      b.LineComment,
+		EndComment:       b.EndComment,
+		Ref:              b.Ref,
 	}
 	for i, entry := range b.Body {
 		out.Body[i] = entry.Clone()
@@ -253,10 +295,13 @@ type MapEntry struct {
 	Pos    lexer.Position `parser:""`
 	Parent Node           `parser:""`
 
-	Comments []string `parser:"@Comment*"`
+	Comments []string `parser:"@(Comment | LineComment)*"`
 
 	Key   Value `parser:"@@ ':'"`
-	Value Value `parser:"@@"`
+	Value Value `parser:"@@ (?= ',' | LineComment? '}') ','?"`
+
+	// LineComment is a comment on the same line as the entry, following its value.
+	LineComment string `parser:"@LineComment?"`
 }
 
 func (e *MapEntry) Detach() bool {
@@ -285,10 +330,11 @@ func (e *MapEntry) Clone() *MapEntry {
 		return nil
 	}
 	return &MapEntry{
-		Pos:      e.Pos,
-		Key:      e.Key.Clone(),
-		Value:    e.Value.Clone(),
-		Comments: cloneStrings(e.Comments),
+		Pos:         e.Pos,
+		Key:         e.Key.Clone(),
+		Value:       e.Value.Clone(),
+		Comments:    cloneStrings(e.Comments),
+		LineComment: e.LineComment,
 	}
 }
 
@@ -361,21 +407,43 @@ type Value interface {
 }
 
 // Type of a Value.
+//
+// Types are either one of the scalar types "string", "number", "boolean",
+// "duration", "time" or "any", or a type parameterised by another type,
+// such as "list(string)", "map(number)" or a named type such as "ip(string)".
 type Type struct {
 	Pos    lexer.Position `parser:""`
 	Parent Node           `parser:""`
 
-	Type string `parser:"@('string':Ident | 'number':Ident | 'boolean':Ident)"`
+	Type string `parser:"( @('string':Ident | 'number':Ident | 'boolean':Ident | 'duration':Ident | 'time':Ident | 'any':Ident) | @Ident '('"`
+	// Of is the parameter of list, map and named types.
+	Of *Type `parser:"  @@ ')' )"`
 }
 
 var _ Value = &Type{}
 
-func (t *Type) value()                      {}
-func (t *Type) Clone() Value                { clone := *t; return &clone }
-func (t *Type) String() string              { return t.Type }
-func (t *Type) Detach() bool                { return false }
-func (t *Type) Position() lexer.Position    { return t.Pos }
-func (t *Type) children() (children []Node) { return nil }
+func (t *Type) value() {}
+func (t *Type) Clone() Value {
+	clone := *t
+	if t.Of != nil {
+		clone.Of = t.Of.Clone().(*Type)
+	}
+	return &clone
+}
+func (t *Type) String() string {
+	if t.Of != nil {
+		return t.Type + "(" + t.Of.String() + ")"
+	}
+	return t.Type
+}
+func (t *Type) Detach() bool             { return false }
+func (t *Type) Position() lexer.Position { return t.Pos }
+func (t *Type) children() (children []Node) {
+	if t.Of != nil {
+		children = append(children, t.Of)
+	}
+	return children
+}
 
 // Call represents a function call.
 type Call struct {
@@ -466,16 +534,73 @@ func (h *Heredoc) GetHeredoc() string {
 // A List of values.
 type List struct {
 	Pos    lexer.Position `parser:""`
+	EndPos lexer.Position `parser:""`
 	Parent Node           `parser:""`
 
-	List []Value `parser:"( '[' ( @@ ( ',' @@ )* )? ','? ']' )"`
+	List []Value
+
+	// Elements holds the comments of list elements, and is only populated by
+	// the parser if the list has comments. The comments of an element are
+	// marshalled for as long as its Value remains in List.
+	Elements []*ListElement `parser:"'[' @@*"`
+
+	// TrailingComments are comments following the last element.
+	TrailingComments CommentList `parser:"@(Comment | LineComment)* ']'"`
+}
+
+// ListElement holds the comments of an element of a List.
+type ListElement struct {
+	Pos lexer.Position `parser:""`
+
+	// Comments preceding the element.
+	Comments CommentList `parser:"@(Comment | LineComment)*"`
+
+	Value Value `parser:"@@ (?= ',' | (Comment | LineComment)* ']') ','?"`
+
+	// LineComment is a comment on the same line as the element, following it.
+	LineComment string `parser:"@LineComment?"`
+}
+
+func (e *ListElement) hasComments() bool {
+	return len(e.Comments) > 0 || e.LineComment != ""
+}
+
+// element returns the ListElement holding the comments of value, if any.
+func (l *List) element(value Value) *ListElement {
+	for _, element := range l.Elements {
+		if element.Value == value {
+			return element
+		}
+	}
+	return nil
+}
+
+// hasComments returns true if the list or any element in it has comments.
+func (l *List) hasComments() bool {
+	if len(l.TrailingComments) > 0 {
+		return true
+	}
+	for _, value := range l.List {
+		if element := l.element(value); element != nil && element.hasComments() {
+			return true
+		}
+	}
+	return false
 }
 
 func (l *List) Clone() Value {
 	out := *l
 	out.List = make([]Value, len(l.List))
+	out.Elements = nil
+	out.TrailingComments = cloneStrings(l.TrailingComments)
 	for i, value := range l.List {
 		out.List[i] = value.Clone()
+		if element := l.element(value); element != nil {
+			clone := *element
+			clone.Comments = cloneStrings(element.Comments)
+			clone.Value = out.List[i]
+			out.Elements = append(out.Elements, &clone)
+		}
 	}
 	return &out
 }
@@ -503,9 +628,10 @@ func (l *List) value()                      {}
 // A Map of key to value.
 type Map struct {
 	Pos    lexer.Position `parser:""`
+	EndPos lexer.Position `parser:""`
 	Parent Node           `parser:""`
 
-	Entries []*MapEntry `parser:"( '{' ( @@ ( ',' @@ )* ','? )? '}' )"`
+	Entries []*MapEntry `parser:"'{' @@* '}'"`
 }
 
 func (m *Map) Clone() Value {
@@ -548,7 +674,7 @@ var (
 			{"Number", `^[-+]?[0-9]*\.?[0-9]+([eE][-+]?[0-9]+)?`, nil},
 			{"Heredoc", `<<[-]?(\w+\b)`, lexer.Push("Heredoc")},
 			{"String", `"(\\\d\d\d|\\.|[^"])*"|'(\\\d\d\d|\\.|[^'])*'`, nil},
-			{"Punct", `[][*?{}=:,()|]`, nil},
+			{"Punct", `[][*?{}=:,()|@]`, nil},
 			{"Comment", `(?:(?://|#)[^\n]*(?:\n[ \t]*(?://|#)[^\n]*)*)|/\*.*?\*/`, nil},
 			{"Whitespace", `\s+`, nil},
 		},
@@ -560,18 +686,94 @@ var (
 	}))
 	numberType = lex.Symbols()["Number"]
 	parser     = participle.MustBuild[AST](
-		participle.Lexer(lex),
+		participle.Lexer(newLineCommentDefinition(lex)),
 		participle.Map(unquoteString, "String"),
 		participle.Map(cleanHeredocStart, "Heredoc"),
-		participle.Map(stripComment, "Comment"),
+		participle.Map(stripComment, "Comment", "LineComment"),
 		participle.Elide("Whitespace"),
-		participle.Union[Entry](&Block{}, &Attribute{}, &Comment{}),
+		participle.Union[Entry](&Block{}, &Attribute{}, &Comment{}, &includeDirective{}),
 		participle.Union[Value](&Bool{}, &Type{}, &String{}, &Number{}, &List{}, &Map{}, &Heredoc{}),
 		// We need lookahead to ensure prefixed comments are associated with the right nodes.
 		participle.UseLookahead(50))
 )
 
-var stripCommentRe = regexp.MustCompile(`^[ \t]*(?://|#|/\*)|\*/$`)
+// lineCommentDefinition wraps a lexer so that comments following other tokens
+// on the same line, such as "// HTTP" in `port = 80 // HTTP`, are lexed as
+// LineComment tokens rather than Comment tokens.
+type lineCommentDefinition struct {
+	lexer.Definition
+	symbols map[string]lexer.TokenType
+}
+
+func newLineCommentDefinition(def lexer.Definition) *lineCommentDefinition {
+	symbols := map[string]lexer.TokenType{}
+	next := lexer.EOF
+	for name, t := range def.Symbols() {
+		symbols[name] = t
+		if t < next {
+			next = t
+		}
+	}
+	symbols["LineComment"] = next - 1
+	return &lineCommentDefinition{Definition: def, symbols: symbols}
+}
+
+func (d *lineCommentDefinition) Symbols() map[string]lexer.TokenType { return d.symbols }
+
+func (d *lineCommentDefinition) Lex(filename string, r io.Reader) (lexer.Lexer, error) {
+	l, err := d.Definition.Lex(filename, r)
+	if err != nil {
+		return nil, err
+	}
+	return &lineCommentLexer{Lexer: l, symbols: d.symbols}, nil
+}
+
+type lineCommentLexer struct {
+	lexer.Lexer
+	symbols map[string]lexer.TokenType
+	// Line on which the last token other than whitespace or a comment ended.
+	line    int
+	pending []lexer.Token
+}
+
+func (l *lineCommentLexer) Next() (lexer.Token, error) {
+	if len(l.pending) > 0 {
+		token := l.pending[0]
+		l.pending = l.pending[1:]
+		return token, nil
+	}
+	token, err := l.Lexer.Next()
+	if err != nil {
+		return token, err
+	}
+	switch token.Type {
+	case l.symbols["Whitespace"], lexer.EOF:
+
+	case l.symbols["Comment"]:
+		if token.Pos.Line != l.line {
+			break
+		}
+		token.Type = l.symbols["LineComment"]
+		// Consecutive line comments are lexed as one token, so split off the
+		// lines following the first.
+		if newline := strings.IndexByte(token.Value, '\n'); newline >= 0 && !strings.HasPrefix(token.Value, "/*") {
+			rest := token.Value[newline:]
+			indent := len(rest) - len(strings.TrimLeft(rest, "\n \t"))
+			whitespace := lexer.Token{Type: l.symbols["Whitespace"], Value: rest[:indent], Pos: token.Pos}
+			whitespace.Pos.Advance(token.Value[:newline])
+			comment := lexer.Token{Type: l.symbols["Comment"], Value: rest[indent:], Pos: whitespace.Pos}
+			comment.Pos.Advance(whitespace.Value)
+			l.pending = append(l.pending, whitespace, comment)
+			token.Value = token.Value[:newline]
+		}
+
+	default:
+		l.line = token.Pos.Line + strings.Count(token.Value, "\n")
+	}
+	return token, nil
+}
+
+var stripCommentRe = regexp.MustCompile(`^[ \t]*(?://|#|/\*)|[ \t]*\*/$`)
 var matchLeadingWhitespaceRe = regexp.MustCompile(`^[ \t]*`)
 
 func stripComment(token lexer.Token) (lexer.Token, error) {
@@ -616,6 +818,12 @@ type ParseOption func(*parseConfig)
 // parseConfig holds the configuration for parsing.
 type parseConfig struct {
 	detachedComments bool
+	filename         string
+	lossless         bool
+	schema           bool
+	includer         fs.FS
+	// Cleaned paths of the files being included, outermost first.
+	includeStack []string
 }
 
 // WithDetachedComments controls whether comments that are not directly associated with a
@@ -628,6 +836,38 @@ func WithDetachedComments(preserve bool) ParseOption {
 	}
 }
 
+// WithFilename sets the filename reported in node positions and errors.
+func WithFilename(filename string) ParseOption {
+	return func(config *parseConfig) {
+		config.filename = filename
+	}
+}
+
+// WithLossless retains the original source in the AST, so that MarshalAST
+// reproduces it byte-for-byte, including comment markers, quoting, spacing and
+// blank lines.
+//
+// Entries that are added, removed or modified after parsing are formatted as
+// usual, with the line endings of the source, while the rest of the source is
+// left untouched. This is useful for tools that make targeted edits to
+// hand-written files.
+func WithLossless(lossless bool) ParseOption {
+	return func(config *parseConfig) {
+		config.lossless = lossless
+	}
+}
+
+// WithSchema parses the document as a schema, such as one produced by Schema.
+//
+// In schemas, "x = type(Node)" refers to the type defined by a top-level
+// "type Node { ... }" block, and is parsed as a Block with Ref set. Otherwise
+// it is an attribute whose value is the named type "type".
+func WithSchema(schema bool) ParseOption {
+	return func(config *parseConfig) {
+		config.schema = schema
+	}
+}
+
 // Parse HCL from an io.Reader.
 func Parse(r io.Reader, options ...ParseOption) (*AST, error) {
 	config := &parseConfig{}
@@ -635,12 +875,20 @@ func Parse(r io.Reader, options ...ParseOption) (*AST, error) {
 		option(config)
 	}
 
-	hcl, err := parser.Parse("", r)
+	if config.lossless {
+		data, err := io.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+		return ParseBytes(data, options...)
+	}
+
+	hcl, err := parser.Parse(config.filename, r)
 	if err != nil {
 		return nil, err
 	}
 
-	return config.postProccessAST(hcl)
+	return config.postProccessAST(hcl, nil)
 }
 
 // ParseString parses HCL from a string.
@@ -650,12 +898,12 @@ func ParseString(str string, options ...ParseOption) (*AST, error) {
 		option(config)
 	}
 
-	hcl, err := parser.ParseString("", str)
+	hcl, err := parser.ParseString(config.filename, str)
 	if err != nil {
 		return nil, err
 	}
 
-	return config.postProccessAST(hcl)
+	return config.postProccessAST(hcl, []byte(str))
 }
 
 // ParseBytes parses HCL from bytes.
@@ -665,20 +913,27 @@ func ParseBytes(data []byte, options ...ParseOption) (*AST, error) {
 		option(config)
 	}
 
-	hcl, err := parser.ParseBytes("", data)
+	hcl, err := parser.ParseBytes(config.filename, data)
 	if err != nil {
 		return nil, err
 	}
 
-	return config.postProccessAST(hcl)
+	return config.postProccessAST(hcl, data)
 }
 
-func (config *parseConfig) postProccessAST(hcl *AST) (*AST, error) {
+func (config *parseConfig) postProccessAST(hcl *AST, source []byte) (*AST, error) {
+	populateListsInEntries(hcl.Entries)
+
 	err := AddParentRefs(hcl)
 	if err != nil {
 		return nil, err
 	}
 
+	err = populateBlockLineComments(hcl)
+	if err != nil {
+		return nil, err
+	}
+
 	// Always process comments to attach them appropriately
 	err = populateAttachedComments(hcl)
 	if err != nil {
@@ -697,6 +952,28 @@ func (config *parseConfig) postProccessAST(hcl *AST) (*AST, error) {
 		}
 	}
 
+	resolved, err := config.resolveIncludes(hcl)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.includeStack) == 0 {
+		err = resolveTypeReferences(hcl, config.schema)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if config.lossless {
+		if resolved {
+			return nil, fmt.Errorf("@include directives can not be combined with WithLossless")
+		}
+		hcl.source, err = newSourceMap(hcl, config.filename, source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	return hcl, nil
 }
 
@@ -744,6 +1021,113 @@ func populateAttachedCommentsInEntries(entries *Entries) {
 	*entries = newEntries
 }
 
+// populateListsInEntries populates List.List from the parsed List.Elements,
+// discarding Elements if no element has comments.
+func populateListsInEntries(entries Entries) {
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case *Block:
+			populateListsInEntries(entry.Body)
+		case *Attribute:
+			populateListsInValue(entry.Value)
+			populateListsInValue(entry.Default)
+			for _, value := range entry.Enum {
+				populateListsInValue(value)
+			}
+		}
+	}
+}
+
+func populateListsInValue(value Value) {
+	switch value := value.(type) {
+	case *List:
+		value.List = make([]Value, len(value.Elements))
+		for i, element := range value.Elements {
+			value.List[i] = element.Value
+			populateListsInValue(element.Value)
+		}
+		if !value.hasComments() {
+			value.Elements = nil
+			value.TrailingComments = nil
+		}
+	case *Map:
+		for _, entry := range value.Entries {
+			populateListsInValue(entry.Key)
+			populateListsInValue(entry.Value)
+		}
+	}
+}
+
+// resolveTypeReferences converts blocks referring to types that are not
+// defined in the AST back into attributes, as "x = type(string)" is also an
+// attribute whose value is the named type "type". Types are only defined in
+// schemas.
+func resolveTypeReferences(ast *AST, schema bool) error {
+	defined := map[string]bool{}
+	for _, entry := range ast.Entries {
+		if block, ok := entry.(*Block); ok && schema && isSchemaDefinition(block) {
+			defined[block.Labels[0]] = true
+		}
+	}
+	var err error
+	convert := func(entries Entries) {
+		for i, entry := range entries {
+			block, ok := entry.(*Block)
+			if !ok || block.Ref == "" || defined[block.Ref] {
+				continue
+			}
+			if len(block.Labels) > 0 || block.Repeated {
+				if err == nil && schema {
+					err = participle.Errorf(block.Pos, "undefined type %q", block.Ref)
+				} else if err == nil {
+					err = participle.Errorf(block.Pos, "type reference %q is only valid in a schema", block.Ref)
+				}
+				continue
+			}
+			entries[i] = &Attribute{
+				Pos:         block.Pos,
+				Parent:      block.Parent,
+				Comments:    block.Comments,
+				Key:         block.Name,
+				Value:       &Type{Pos: block.Pos, Type: "type", Of: &Type{Pos: block.Pos, Type: block.Ref}},
+				LineComment: block.EndComment,
+			}
+			addParentRefs(block.Parent, entries[i])
+		}
+	}
+	convert(ast.Entries)
+	_ = visitBlocks(ast, func(block *Block) error {
+		convert(block.Body)
+		return nil
+	})
+	return err
+}
+
+// populateBlockLineComments moves comments on the same line as the closing
+// brace of a block into the block's EndComment.
+func populateBlockLineComments(ast *AST) error {
+	populateBlockLineCommentsInEntries(&ast.Entries)
+
+	return visitBlocks(ast, func(block *Block) error {
+		populateBlockLineCommentsInEntries(&block.Body)
+		return nil
+	})
+}
+
+func populateBlockLineCommentsInEntries(entries *Entries) {
+	newEntries := make(Entries, 0, len(*entries))
+	for i, entry := range *entries {
+		if comment, ok := entry.(*Comment); ok && i > 0 {
+			if block, ok := (*entries)[i-1].(*Block); ok && block.EndComment == "" && comment.Pos.Line == block.EndPos.Line {
+				block.EndComment = strings.Join(comment.Comments, "\n")
+				continue
+			}
+		}
+		newEntries = append(newEntries, entry)
+	}
+	*entries = newEntries
+}
+
 // populateTrailingComments copies trailing comments from Comment nodes to TrailingComments fields.
 func populateTrailingComments(ast *AST) error {
 	populateTrailingCommentsInEntries(&ast.Entries, &ast.TrailingComments)
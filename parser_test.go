@@ -322,6 +322,88 @@ EOF
 	}
 }
 
+func TestLineComments(t *testing.T) {
+	ast, err := ParseString(`
+		port = 80 // HTTP
+		// Attached to host.
+		host = "localhost" # local
+		service web { // The web service.
+			hosts = [
+				"a", // first
+				"b"  // last
+			]
+		}
+		service db {
+			labels = {
+				"env": "prod", // environment
+				"tier": "data" /* tier */
+			}
+		} // The database.
+		next = true
+	`)
+	assert.NoError(t, err)
+
+	port := ast.Entries[0].(*Attribute)
+	assert.Equal(t, "HTTP", port.LineComment)
+	assert.Equal(t, 0, len(port.Comments))
+
+	host := ast.Entries[1].(*Attribute)
+	assert.Equal(t, "local", host.LineComment)
+	assert.Equal(t, []string{"Attached to host."}, []string(host.Comments))
+
+	web := ast.Entries[2].(*Block)
+	assert.Equal(t, "The web service.", web.LineComment)
+	hosts := web.Body[0].(*Attribute).Value.(*List)
+	assert.Equal(t, 2, len(hosts.List))
+	assert.Equal(t, "first", hosts.element(hosts.List[0]).LineComment)
+	assert.Equal(t, "last", hosts.element(hosts.List[1]).LineComment)
+	assert.Equal[Node](t, web, hosts.List[0].(*String).Parent.(*List).Parent.(*Attribute).Parent)
+
+	db := ast.Entries[3].(*Block)
+	assert.Equal(t, "", db.LineComment)
+	assert.Equal(t, "The database.", db.EndComment)
+	labels := db.Body[0].(*Attribute).Value.(*Map)
+	assert.Equal(t, "environment", labels.Entries[0].LineComment)
+	assert.Equal(t, "tier", labels.Entries[1].LineComment)
+
+	next := ast.Entries[4].(*Attribute)
+	assert.Equal(t, 0, len(next.Comments))
+
+	// Lists without comments do not retain their elements.
+	ast, err = ParseString(`list = [1, 2]`)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, len(ast.Entries[0].(*Attribute).Value.(*List).Elements))
+}
+
+func TestListComments(t *testing.T) {
+	ast, err := ParseString(`
+		allow = [ // Allowed hosts.
+			// Internal.
+			"10.0.0.1",
+			# External,
+			# for the API.
+			"api.example.com", // api
+			"cdn.example.com"
+			// Disabled: "old.example.com"
+		]
+	`)
+	assert.NoError(t, err)
+	list := ast.Entries[0].(*Attribute).Value.(*List)
+	assert.Equal(t, 3, len(list.List))
+	assert.Equal(t, "cdn.example.com", list.List[2].(*String).Str)
+
+	first := list.element(list.List[0])
+	assert.Equal(t, []string{"Allowed hosts.", "Internal."}, []string(first.Comments))
+	second := list.element(list.List[1])
+	assert.Equal(t, []string{"External,", "for the API."}, []string(second.Comments))
+	assert.Equal(t, "api", second.LineComment)
+	assert.False(t, list.element(list.List[2]).hasComments())
+	assert.Equal(t, []string{`Disabled: "old.example.com"`}, []string(list.TrailingComments))
+
+	clone := ast.Clone().Entries[0].(*Attribute).Value.(*List)
+	assert.Equal(t, []string{"External,", "for the API."}, []string(clone.element(clone.List[1]).Comments))
+}
+
 func TestHeredocIndented(t *testing.T) {
 	hcl, err := ParseString(`
 	doc = <<-EOF
@@ -360,6 +442,7 @@ func normaliseEntries(entries []Entry) {
 
 		case *Block:
 			entry.Pos = lexer.Position{}
+			entry.EndPos = lexer.Position{}
 			entry.Parent = nil
 			normaliseEntries(entry.Body)
 
@@ -383,6 +466,9 @@ func normaliseValue(val Value) {
 	}
 	rv = reflect.Indirect(rv)
 	rv.FieldByName("Pos").Set(reflect.ValueOf(lexer.Position{}))
+	if end := rv.FieldByName("EndPos"); end.IsValid() {
+		end.Set(reflect.ValueOf(lexer.Position{}))
+	}
 	parent := rv.FieldByName("Parent")
 	parent.Set(reflect.Zero(parent.Type()))
 	switch val := val.(type) {
@@ -514,3 +600,46 @@ func TestFunctionalOptions(t *testing.T) {
 		assert.Equal(t, expected, ast)
 	})
 }
+
+func TestParseTypeReferences(t *testing.T) {
+	// Without a type definition, "type(...)" is a named type.
+	ast, err := ParseString("x = type(string) // named\n")
+	assert.NoError(t, err)
+	attr, ok := ast.Entries[0].(*Attribute)
+	assert.True(t, ok)
+	assert.Equal(t, "type(string)", attr.Value.String())
+	assert.Equal(t, "named", attr.LineComment)
+	data, err := MarshalAST(ast)
+	assert.NoError(t, err)
+	assert.Equal(t, "x = type(string) // named\n", string(data))
+
+	ast, err = ParseString(`
+menu(repeated) name = type(Menu)
+
+type Menu {
+  item(repeated) name = type(Menu) // children
+}
+`, WithSchema(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "Menu", ast.Entries[0].(*Block).Ref)
+	item := ast.Entries[1].(*Block).Body[0].(*Block)
+	assert.Equal(t, "Menu", item.Ref)
+	assert.Equal(t, "children", item.EndComment)
+
+	_, err = ParseString(`menu name = type(Menu)`, WithSchema(true))
+	assert.EqualError(t, err, `1:1: undefined type "Menu"`)
+
+	// Outside schemas, type blocks are ordinary blocks and "type(...)" is
+	// always a named type.
+	ast, err = ParseString(`
+type "foo" {}
+y = type(foo)
+`)
+	assert.NoError(t, err)
+	attr, ok = ast.Entries[1].(*Attribute)
+	assert.True(t, ok)
+	assert.Equal(t, "type(foo)", attr.Value.String())
+
+	_, err = ParseString(`menu name = type(Menu)`)
+	assert.EqualError(t, err, `1:1: type reference "Menu" is only valid in a schema`)
+}
@@ -0,0 +1,76 @@
+package hcl
+
+import (
+	"strings"
+)
+
+// Provenance records where the value of each field decoded by UnmarshalAST
+// came from, keyed by the path of the field in the form accepted by Query,
+// eg. "service[web].port".
+type Provenance map[string]Origin
+
+// Origin is where the value of a decoded field came from.
+type Origin struct {
+	// Pos is the position of the attribute or block the value was decoded from.
+	Pos Position
+	// Default is true if the value is from the default:"" tag of the field.
+	Default bool
+	// Implicit is true if the field is a block hydrated by HydratedImplicitBlocks.
+	Implicit bool
+}
+
+func (o Origin) String() string {
+	switch {
+	case o.Default:
+		return "default from tag"
+	case o.Implicit:
+		return "implicit block"
+	default:
+		return o.Pos.String()
+	}
+}
+
+// WithProvenance records the origin of each decoded field in provenance.
+//
+// The filenames of positions are those given to WithFilename when parsing, so
+// when decoding the result of MergeAST they identify the file each value came
+// from.
+func WithProvenance(provenance Provenance) MarshalOption {
+	return func(options *marshalState) {
+		options.provenance = provenance
+	}
+}
+
+// record the origin of the field with the given path segment.
+func (m *marshalState) record(segment string, origin Origin) {
+	if m.provenance != nil {
+		m.provenance[m.fieldPath(segment)] = origin
+	}
+}
+
+// withPath creates a shallow clone for decoding the fields below segment.
+func (m *marshalState) withPath(segment string) *marshalState {
+	if m.provenance == nil {
+		return m
+	}
+	out := *m
+	out.path = m.fieldPath(segment)
+	return &out
+}
+
+func (m *marshalState) fieldPath(segment string) string {
+	if m.path == "" {
+		return segment
+	}
+	return m.path + "." + segment
+}
+
+// blockPathSegment returns the Query path segment matching a block.
+func blockPathSegment(block *Block) string {
+	w := &strings.Builder{}
+	w.WriteString(quotePathName(block.Name))
+	for _, label := range block.Labels {
+		w.WriteString("[" + quotePathName(label) + "]")
+	}
+	return w.String()
+}
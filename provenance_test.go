@@ -0,0 +1,54 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestProvenance(t *testing.T) {
+	type limits struct {
+		CPU int `hcl:"cpu" default:"1"`
+	}
+	type service struct {
+		Name     string  `hcl:"name,label"`
+		Replicas int     `hcl:"replicas"`
+		Limits   *limits `hcl:"limits,block"`
+	}
+	type config struct {
+		Version  int        `hcl:"version"`
+		Timeout  string     `hcl:"timeout" default:"10s"`
+		Services []*service `hcl:"service,block"`
+	}
+	base, err := ParseString(`
+version = 1
+service web {
+  replicas = 1
+}
+`, WithFilename("base.hcl"))
+	assert.NoError(t, err)
+	prod, err := ParseString(`
+service web {
+  replicas = 3
+}
+`, WithFilename("prod.hcl"))
+	assert.NoError(t, err)
+	merged, err := MergeAST(base, []*AST{prod})
+	assert.NoError(t, err)
+
+	provenance := Provenance{}
+	err = UnmarshalAST(merged, &config{}, WithProvenance(provenance), HydratedImplicitBlocks(true))
+	assert.NoError(t, err)
+	actual := map[string]string{}
+	for path, origin := range provenance {
+		actual[path] = origin.String()
+	}
+	assert.Equal(t, map[string]string{
+		"version":                 "base.hcl:2:1",
+		"timeout":                 "default from tag",
+		"service[web]":            "base.hcl:3:1",
+		"service[web].replicas":   "prod.hcl:3:3",
+		"service[web].limits":     "implicit block",
+		"service[web].limits.cpu": "default from tag",
+	}, actual)
+}
@@ -0,0 +1,304 @@
+package hcl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Query returns the blocks, attributes and map entries in the AST matching a
+// path expression.
+//
+// A path is a sequence of "."-separated segments, each selecting children of
+// the nodes matched by the previous segment:
+//
+//	name          blocks, attributes or map entries named "name"
+//	*             all children
+//	**            the current nodes and all their descendants
+//	name[label]   blocks whose first label is "label"
+//	name[a][*]    blocks whose first label is "a" with at least two labels
+//
+// Names and labels may be double-quoted if they contain other than letters,
+// digits, "_" or "-". The children of an attribute are the entries of its map
+// value, so map keys can be selected in the same way as attributes.
+//
+// For example, this selects the port attribute of every listener in the
+// "web" service block:
+//
+//	service[web].listener.port
+//
+// And this selects every port attribute or map entry at any depth:
+//
+//	**.port
+func Query(node Node, expr string) ([]Node, error) {
+	path, err := parsePath(expr)
+	if err != nil {
+		return nil, err
+	}
+	return path.match(node), nil
+}
+
+// MustQuery is like Query but panics if expr is invalid.
+func MustQuery(node Node, expr string) []Node {
+	nodes, err := Query(node, expr)
+	if err != nil {
+		panic(err)
+	}
+	return nodes
+}
+
+type path []pathSegment
+
+type pathSegment struct {
+	name      string
+	wildcard  bool
+	recursive bool
+	labels    []labelPredicate
+}
+
+type labelPredicate struct {
+	label    string
+	wildcard bool
+}
+
+func (s pathSegment) String() string {
+	out := &strings.Builder{}
+	switch {
+	case s.recursive:
+		out.WriteString("**")
+	case s.wildcard:
+		out.WriteString("*")
+	default:
+		out.WriteString(quotePathName(s.name))
+	}
+	for _, label := range s.labels {
+		if label.wildcard {
+			out.WriteString("[*]")
+		} else {
+			fmt.Fprintf(out, "[%s]", quotePathName(label.label))
+		}
+	}
+	return out.String()
+}
+
+func (p path) String() string {
+	parts := make([]string, len(p))
+	for i, segment := range p {
+		parts[i] = segment.String()
+	}
+	return strings.Join(parts, ".")
+}
+
+func quotePathName(name string) string {
+	if name == "" || needsQuote.MatchString(name) {
+		return strconv.Quote(name)
+	}
+	return name
+}
+
+func parsePath(expr string) (path, error) {
+	p := &pathParser{expr: expr}
+	out := path{}
+	for {
+		segment, err := p.parseSegment()
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, segment)
+		if p.pos == len(expr) {
+			return out, nil
+		}
+		if expr[p.pos] != '.' {
+			return nil, p.errorf("expected \".\" or \"[\"")
+		}
+		p.pos++
+	}
+}
+
+type pathParser struct {
+	expr string
+	pos  int
+}
+
+func (p *pathParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("invalid path %q at offset %d: %s", p.expr, p.pos, fmt.Sprintf(format, args...))
+}
+
+func (p *pathParser) parseSegment() (pathSegment, error) {
+	segment := pathSegment{}
+	switch {
+	case strings.HasPrefix(p.expr[p.pos:], "**"):
+		p.pos += 2
+		segment.recursive = true
+		return segment, nil
+
+	case strings.HasPrefix(p.expr[p.pos:], "*"):
+		p.pos++
+		segment.wildcard = true
+
+	default:
+		name, err := p.parseName()
+		if err != nil {
+			return segment, err
+		}
+		segment.name = name
+	}
+	for p.pos < len(p.expr) && p.expr[p.pos] == '[' {
+		p.pos++
+		predicate := labelPredicate{}
+		if strings.HasPrefix(p.expr[p.pos:], "*") {
+			p.pos++
+			predicate.wildcard = true
+		} else {
+			label, err := p.parseName()
+			if err != nil {
+				return segment, err
+			}
+			predicate.label = label
+		}
+		if p.pos >= len(p.expr) || p.expr[p.pos] != ']' {
+			return segment, p.errorf("expected \"]\"")
+		}
+		p.pos++
+		segment.labels = append(segment.labels, predicate)
+	}
+	return segment, nil
+}
+
+func (p *pathParser) parseName() (string, error) {
+	rest := p.expr[p.pos:]
+	if strings.HasPrefix(rest, `"`) {
+		end := 1
+		for end < len(rest) && rest[end] != '"' {
+			if rest[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(rest) {
+			return "", p.errorf("unterminated string")
+		}
+		name, err := strconv.Unquote(rest[:end+1])
+		if err != nil {
+			return "", p.errorf("%s", err)
+		}
+		p.pos += end + 1
+		return name, nil
+	}
+	end := strings.IndexFunc(rest, func(r rune) bool {
+		return !(r == '_' || r == '-' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+	if end < 0 {
+		end = len(rest)
+	}
+	if end == 0 {
+		return "", p.errorf("expected a name")
+	}
+	p.pos += end
+	return rest[:end], nil
+}
+
+func (p path) match(node Node) []Node {
+	nodes := []Node{node}
+	for _, segment := range p {
+		var next []Node
+		seen := map[Node]bool{}
+		add := func(node Node) {
+			if !seen[node] {
+				seen[node] = true
+				next = append(next, node)
+			}
+		}
+		for _, node := range nodes {
+			if segment.recursive {
+				add(node)
+				walkPathChildren(node, add)
+				continue
+			}
+			for _, child := range pathChildren(node) {
+				if segment.matches(child) {
+					add(child)
+				}
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (s pathSegment) matches(node Node) bool {
+	if !s.wildcard && pathKey(node) != s.name {
+		return false
+	}
+	if len(s.labels) == 0 {
+		return true
+	}
+	block, ok := node.(*Block)
+	if !ok || len(block.Labels) < len(s.labels) {
+		return false
+	}
+	for i, predicate := range s.labels {
+		if !predicate.wildcard && block.Labels[i] != predicate.label {
+			return false
+		}
+	}
+	return true
+}
+
+func walkPathChildren(node Node, visit func(Node)) {
+	for _, child := range pathChildren(node) {
+		visit(child)
+		walkPathChildren(child, visit)
+	}
+}
+
+// pathChildren returns the children of node addressable by a path.
+func pathChildren(node Node) []Node {
+	var entries []Entry
+	switch node := node.(type) {
+	case *AST:
+		entries = node.Entries
+	case *Block:
+		entries = node.Body
+	case *Attribute:
+		return mapEntryNodes(node.Value)
+	case *MapEntry:
+		return mapEntryNodes(node.Value)
+	}
+	out := make([]Node, 0, len(entries))
+	for _, entry := range entries {
+		switch entry.(type) {
+		case *Block, *Attribute:
+			out = append(out, entry)
+		}
+	}
+	return out
+}
+
+func mapEntryNodes(value Value) []Node {
+	m, ok := value.(*Map)
+	if !ok {
+		return nil
+	}
+	out := make([]Node, len(m.Entries))
+	for i, entry := range m.Entries {
+		out[i] = entry
+	}
+	return out
+}
+
+// pathKey returns the name of a node addressable by a path.
+func pathKey(node Node) string {
+	switch node := node.(type) {
+	case *Block:
+		return node.Name
+	case *Attribute:
+		return node.Key
+	case *MapEntry:
+		if str, ok := node.Key.(*String); ok {
+			return str.Str
+		}
+		return node.Key.String()
+	}
+	return ""
+}
@@ -0,0 +1,96 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+const queryHCL = `
+service web {
+  listener {
+    port = 80
+  }
+  listener {
+    port = 443
+  }
+  labels = {
+    "env": "prod",
+    "my key": "value",
+  }
+}
+
+service db {
+  port = 5432
+}
+
+route "a" "b" {}
+route "a" "c" {}
+`
+
+func TestQuery(t *testing.T) {
+	ast, err := ParseString(queryHCL)
+	assert.NoError(t, err)
+	tests := []struct {
+		expr     string
+		expected []string
+	}{
+		{"service", []string{"service web", "service db"}},
+		{"service[web].listener.port", []string{"port = 80", "port = 443"}},
+		{"service[*].port", []string{"port = 5432"}},
+		{"service.*", []string{"listener", "listener", `labels = {"env": "prod", "my key": "value"}`, "port = 5432"}},
+		{"**.port", []string{"port = 80", "port = 443", "port = 5432"}},
+		{"service[web].labels.env", []string{`"env": "prod"`}},
+		{`service[web].labels."my key"`, []string{`"my key": "value"`}},
+		{"route[a][c]", []string{"route a c"}},
+		{"route[*][b]", []string{"route a b"}},
+		{"route[a][b][c]", nil},
+		{"missing", nil},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			nodes, err := Query(ast, test.expr)
+			assert.NoError(t, err)
+			var actual []string
+			for _, node := range nodes {
+				actual = append(actual, describeNode(node))
+			}
+			assert.Equal(t, test.expected, actual)
+		})
+	}
+}
+
+func TestQueryErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		fail string
+	}{
+		{"", `invalid path "" at offset 0: expected a name`},
+		{"a.", `invalid path "a." at offset 2: expected a name`},
+		{"a[b", `invalid path "a[b" at offset 3: expected "]"`},
+		{"a b", `invalid path "a b" at offset 1: expected "." or "["`},
+		{`"a`, `invalid path "\"a" at offset 0: unterminated string`},
+	}
+	for _, test := range tests {
+		t.Run(test.expr, func(t *testing.T) {
+			_, err := Query(&AST{}, test.expr)
+			assert.EqualError(t, err, test.fail)
+		})
+	}
+}
+
+func describeNode(node Node) string {
+	switch node := node.(type) {
+	case *Block:
+		out := node.Name
+		for _, label := range node.Labels {
+			out += " " + label
+		}
+		return out
+	case *Attribute:
+		return node.String()
+	case *MapEntry:
+		return node.Key.String() + ": " + node.Value.String()
+	}
+	return ""
+}
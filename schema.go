@@ -7,13 +7,28 @@ import (
 
 // Schema reflects a schema from a Go value.
 //
-// A schema is itself HCL.
+// A schema is itself HCL. Blocks of recursive struct types refer to a type
+// definition named after the Go type with Block.Ref, and the definitions are
+// appended to the schema as blocks named "type". For example, the schema of a
+// []Menu field tagged hcl:"menu,block", where Menu has a "name" label and an
+// "item" block of []Menu, is:
+//
+//	menu(repeated) name = type(Menu)
+//
+//	type Menu {
+//	  item(repeated) name = type(Menu)
+//	}
+//
+// Parse schemas WithSchema to resolve these references.
 func Schema(v interface{}, options ...MarshalOption) (*AST, error) {
 	options = append(options, asSchema())
-	ast, err := marshalToAST(v, newMarshalState(options...))
+	opt := newMarshalState(options...)
+	opt.types = newSchemaTypes(reflect.TypeOf(v), opt)
+	ast, err := marshalToAST(v, opt)
 	if err != nil {
 		return nil, err
 	}
+	ast.Entries = append(ast.Entries, opt.types.entries()...)
 	return ast, nil
 }
 
@@ -33,12 +48,20 @@ func BlockSchema(name string, v interface{}, options ...MarshalOption) (*AST, er
 		return nil, fmt.Errorf("expected a pointer to a struct not %T", v)
 	}
 	options = append(options, asSchema())
-	block, err := valueToBlock(rv.Elem(), tag{name: name, block: true}, newMarshalState(options...))
+	opt := newMarshalState(options...)
+	opt.types = newSchemaTypes(rv.Type(), opt)
+	var block *Block
+	var err error
+	if opt.types.recursive[rv.Elem().Type()] {
+		block, err = opt.types.reference(rv.Elem().Type(), tag{name: name, block: true}, false, opt)
+	} else {
+		block, err = valueToBlock(rv.Elem(), tag{name: name, block: true}, opt)
+	}
 	if err != nil {
 		return nil, err
 	}
 	return &AST{
-		Entries: []Entry{block},
+		Entries: append([]Entry{block}, opt.types.entries()...),
 		Schema:  true,
 	}, nil
 }
@@ -56,13 +79,48 @@ var (
 	strType  = "string"
 	numType  = "number"
 	boolType = "boolean"
+	durType  = "duration"
+	tsType   = "time"
+	anyType  = "any"
+	listType = "list"
+	mapType  = "map"
 )
 
+// SchemaTyper may be implemented by the types of attributes to name their
+// type in schemas. The named type is parameterised by the schema type of its
+// representation, eg. "ip(string)".
+type SchemaTyper interface {
+	SchemaType() string
+}
+
+var schemaTyperInterface = reflect.TypeOf((*SchemaTyper)(nil)).Elem()
+
 func attrSchema(t reflect.Type) (Value, error) {
-	if t == durationType || t == timeType || typeImplements(t, textUnmarshalerInterface) || typeImplements(t, jsonUnmarshalerInterface) {
+	if t.Kind() != reflect.Ptr && t.Kind() != reflect.Interface && typeImplements(t, schemaTyperInterface) {
+		of, err := underlyingAttrSchema(t)
+		if err != nil {
+			return nil, err
+		}
+		name := reflect.New(t).Interface().(SchemaTyper).SchemaType()
+		return &Type{Type: name, Of: asType(of)}, nil
+	}
+	return underlyingAttrSchema(t)
+}
+
+// underlyingAttrSchema reflects the schema of t, ignoring any SchemaType method.
+func underlyingAttrSchema(t reflect.Type) (Value, error) {
+	switch {
+	case t == durationType:
+		return &Type{Type: durType}, nil
+	case t == timeType:
+		return &Type{Type: tsType}, nil
+	case typeImplements(t, textUnmarshalerInterface) || typeImplements(t, jsonUnmarshalerInterface):
 		return &Type{Type: strType}, nil
 	}
 	switch t.Kind() {
+	case reflect.Interface:
+		return &Type{Type: anyType}, nil
+
 	case reflect.String:
 		return &Type{Type: strType}, nil
 
@@ -103,13 +161,186 @@ func attrSchema(t reflect.Type) (Value, error) {
 	}
 }
 
+// asType converts a schema value to the equivalent Type, eg. "[string]" to
+// "list(string)".
+func asType(value Value) *Type {
+	switch value := value.(type) {
+	case *Type:
+		return value
+	case *List:
+		if len(value.List) == 1 {
+			return &Type{Type: listType, Of: asType(value.List[0])}
+		}
+	case *Map:
+		if len(value.Entries) == 1 {
+			return &Type{Type: mapType, Of: asType(value.Entries[0].Value)}
+		}
+	}
+	return &Type{Type: anyType}
+}
+
 func sliceToBlockSchema(t reflect.Type, tag tag, opt *marshalState) (*Block, error) {
 	block := &Block{
-		Name:     tag.name,
-		Comments: tag.comments(opt),
-		Repeated: true,
+		Name:       tag.name,
+		Comments:   tag.comments(opt),
+		Repeated:   true,
+		Aliases:    tag.aliases,
+		Deprecated: tag.deprecated,
 	}
 	var err error
 	block.Body, block.Labels, err = structToEntries(reflect.New(t.Elem()).Elem(), opt.withSchema(true))
-	return block, err
+	if err != nil {
+		return nil, err
+	}
+	block.Comments = append(block.Comments, labelComments(t.Elem(), opt)...)
+	return block, nil
+}
+
+// schemaTypes tracks the recursive struct types of a schema, which are
+// defined once and referenced by name.
+type schemaTypes struct {
+	recursive   map[reflect.Type]bool
+	names       map[reflect.Type]string
+	taken       map[string]bool
+	definitions []*Block
+}
+
+// newSchemaTypes finds the recursive struct types reachable through blocks
+// from t.
+func newSchemaTypes(t reflect.Type, opt *marshalState) *schemaTypes {
+	s := &schemaTypes{
+		recursive: map[reflect.Type]bool{},
+		names:     map[reflect.Type]string{},
+		taken:     map[string]bool{},
+	}
+	s.find(t, map[reflect.Type]bool{}, map[reflect.Type]bool{}, opt)
+	return s
+}
+
+func (s *schemaTypes) find(t reflect.Type, inProgress, done map[reflect.Type]bool, opt *marshalState) {
+	t = blockStructType(t)
+	if t.Kind() != reflect.Struct {
+		return
+	}
+	if inProgress[t] {
+		s.recursive[t] = true
+		return
+	}
+	if done[t] {
+		return
+	}
+	inProgress[t] = true
+	defer func() {
+		delete(inProgress, t)
+		done[t] = true
+	}()
+	fields, err := flattenFields(reflect.New(t).Elem(), opt)
+	if err != nil {
+		return
+	}
+	for _, field := range fields {
+		if field.tag.block {
+			s.find(field.v.Type(), inProgress, done, opt)
+		}
+	}
+}
+
+// reference returns a block referring to the definition of the recursive
+// type t, defining it if necessary.
+func (s *schemaTypes) reference(t reflect.Type, tag tag, repeated bool, opt *marshalState) (*Block, error) {
+	name, err := s.define(t, tag, opt)
+	if err != nil {
+		return nil, err
+	}
+	var labels []string
+	if fields, err := flattenFields(reflect.New(t).Elem(), opt); err == nil {
+		for _, field := range fields {
+			if field.tag.label {
+				labels = append(labels, field.tag.name)
+			}
+		}
+	}
+	return &Block{
+		Name:       tag.name,
+		Comments:   append(tag.comments(opt), labelComments(t, opt)...),
+		Repeated:   repeated,
+		Aliases:    tag.aliases,
+		Deprecated: tag.deprecated,
+		Labels:     labels,
+		Ref:        name,
+	}, nil
+}
+
+func (s *schemaTypes) define(t reflect.Type, tag tag, opt *marshalState) (string, error) {
+	if name, ok := s.names[t]; ok {
+		return name, nil
+	}
+	name := t.Name()
+	if name == "" {
+		name = tag.name
+	}
+	for base, i := name, 2; s.taken[name]; i++ {
+		name = fmt.Sprintf("%s%d", base, i)
+	}
+	s.names[t] = name
+	s.taken[name] = true
+	definition := &Block{Name: "type", Labels: []string{name}}
+	s.definitions = append(s.definitions, definition)
+	// The body is reflected afresh, as the type may be in the process of
+	// being reflected.
+	definitionOpt := *opt
+	definitionOpt.seenStructs = map[reflect.Type]bool{}
+	var err error
+	definition.Body, _, err = structToEntries(reflect.New(t).Elem(), &definitionOpt)
+	return name, err
+}
+
+func (s *schemaTypes) entries() []Entry {
+	entries := make([]Entry, len(s.definitions))
+	for i, definition := range s.definitions {
+		entries[i] = definition
+	}
+	return entries
+}
+
+// blockStructType returns the struct type of a block field of type t.
+func blockStructType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	return t
+}
+
+// schemaDefinitions returns the type definitions of a schema referenced by
+// Block.Ref, keyed by name.
+func schemaDefinitions(schema *AST) map[string]*Block {
+	refs := map[string]bool{}
+	_ = Visit(schema, func(node Node, next func() error) error {
+		if block, ok := node.(*Block); ok && block.Ref != "" {
+			refs[block.Ref] = true
+		}
+		return next()
+	})
+	definitions := map[string]*Block{}
+	for _, entry := range schema.Entries {
+		if block, ok := entry.(*Block); ok && isSchemaDefinition(block) && refs[block.Labels[0]] {
+			definitions[block.Labels[0]] = block
+		}
+	}
+	return definitions
+}
+
+func isSchemaDefinition(block *Block) bool {
+	return block.Name == "type" && len(block.Labels) == 1 && block.Ref == ""
+}
+
+// withoutDefinitions returns entries without the type definitions.
+func withoutDefinitions(entries []Entry, definitions map[string]*Block) (out []Entry) {
+	for _, entry := range entries {
+		if block, ok := entry.(*Block); ok && isSchemaDefinition(block) && definitions[block.Labels[0]] == block {
+			continue
+		}
+		out = append(out, entry)
+	}
+	return out
 }
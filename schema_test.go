@@ -3,6 +3,7 @@ package hcl
 import (
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/alecthomas/assert/v2"
 )
@@ -149,13 +150,129 @@ func TestRecursiveSchema(t *testing.T) {
 	assert.NoError(t, err)
 	schema, err := MarshalAST(ast)
 	assert.NoError(t, err)
-	assert.Equal(t, `// Name of user.
+	expected := `// Name of user.
 name = string
 // Age of user.
 age = number(optional)
 
+recursive = type(RecursiveSchema)
+
+type RecursiveSchema {
+  // Name of user.
+  name = string
+  // Age of user.
+  age = number(optional)
+
+  recursive = type(RecursiveSchema)
+}
+`
+	assert.Equal(t, expected, string(schema))
+
+	parsed, err := ParseBytes(schema, WithSchema(true))
+	assert.NoError(t, err)
+	assert.Equal(t, "RecursiveSchema", parsed.Entries[2].(*Block).Ref)
+	schema, err = MarshalAST(parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(schema))
+
+	doc, err := ParseString(`
+name = "a"
 recursive {
-  // (recursive)
+  name = "b"
+  recursive {
+    age = 3
+  }
 }
-`, string(schema))
+`)
+	assert.NoError(t, err)
+	errs := ValidateAST(doc, parsed)
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], `5:3: missing required attribute "recursive.recursive.name"`)
+}
+
+func TestRecursiveEntry(t *testing.T) {
+	ast := &AST{Entries: []Entry{&Block{Name: "node", Body: []Entry{&RecursiveEntry{}}}}}
+	assert.Equal(t, "", (&RecursiveEntry{}).EntryKey())
+	assert.Equal(t, 1, len(Find(ast, "node")))
+	clone := ast.Clone()
+	_, ok := clone.Entries[0].(*Block).Body[0].(*RecursiveEntry)
+	assert.True(t, ok)
+	data, err := MarshalAST(clone)
+	assert.NoError(t, err)
+	assert.Equal(t, "node {\n  // (recursive)\n}\n", string(data))
+}
+
+type ipAddress string
+
+func (ipAddress) SchemaType() string { return "ip" }
+
+func TestSchemaTypes(t *testing.T) {
+	type config struct {
+		Timeout time.Duration          `hcl:"timeout"`
+		At      time.Time              `hcl:"at"`
+		Extra   interface{}            `hcl:"extra"`
+		Addr    ipAddress              `hcl:"addr"`
+		Addrs   []ipAddress            `hcl:"addrs"`
+		Meta    map[string]interface{} `hcl:"meta"`
+	}
+	schema, err := Schema(&config{})
+	assert.NoError(t, err)
+	data, err := MarshalAST(schema)
+	assert.NoError(t, err)
+	expected := `timeout = duration
+at = time
+extra = any
+addr = ip(string)
+addrs = [ip(string)]
+meta = {
+  string: any,
+}
+`
+	assert.Equal(t, expected, string(data))
+
+	actual, err := ParseBytes(data)
+	assert.NoError(t, err)
+	data, err = MarshalAST(actual)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, string(data))
+
+	actual, err = ParseString("a = list(map(number))\nb = list\n")
+	assert.NoError(t, err)
+	assert.Equal(t, "list(map(number))", actual.Entries[0].(*Attribute).Value.String())
+	assert.Equal(t, `"list"`, actual.Entries[1].(*Attribute).Value.String())
+}
+
+func TestSchemaLabelConstraints(t *testing.T) {
+	schema, err := Schema(&labelConstrainedConfig{})
+	assert.NoError(t, err)
+	data, err := MarshalAST(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, "// Label kind: Kind of service.\n"+
+		"// Label kind must be one of \"web\", \"db\".\n"+
+		"// Label name must match `^[a-z]+$`.\n"+
+		"// Label tags must match `^[a-z]+$`.\n"+
+		`service(repeated) kind name tags {
+  port = number
+}
+`, string(data))
+}
+
+func TestSchemaAliases(t *testing.T) {
+	schema, err := Schema(&aliasedConfig{})
+	assert.NoError(t, err)
+	data, err := MarshalAST(schema)
+	assert.NoError(t, err)
+	assert.Equal(t, `bind = string(aliases("listen_addr", "addr") deprecated("use bind instead"))
+timeout = number(optional deprecated("timeouts are no longer used"))
+
+tls(aliases("ssl")) {
+  cert = string
+}
+`, string(data))
+
+	parsed, err := ParseBytes(data)
+	assert.NoError(t, err)
+	reparsed, err := MarshalAST(parsed)
+	assert.NoError(t, err)
+	assert.Equal(t, string(data), string(reparsed))
 }
@@ -5,9 +5,11 @@ import (
 	"encoding/json"
 	"fmt"
 	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/alecthomas/participle/v2"
@@ -20,6 +22,7 @@ var (
 	jsonUnmarshalerInterface = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
 	jsonMarshalerInterface   = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
 	remainType               = reflect.TypeOf([]Entry{})
+	positionsType            = reflect.TypeOf(map[string]Position{})
 	durationType             = reflect.TypeOf(time.Duration(0))
 	timeType                 = reflect.TypeOf(time.Time{})
 )
@@ -46,6 +49,12 @@ func UnmarshalAST(ast *AST, v interface{}, options ...MarshalOption) error {
 	for _, option := range options {
 		option(opt)
 	}
+	if opt.migrations != nil {
+		ast = ast.Clone()
+		if _, err := opt.migrations.Migrate(ast); err != nil {
+			return err
+		}
+	}
 	return unmarshalEntries(rv.Elem(), ast.Entries, opt)
 }
 
@@ -95,6 +104,45 @@ func unmarshalEntries(v reflect.Value, entries []Entry, opt *marshalState) error
 	if err != nil {
 		return err
 	}
+	// Decode entries named by aliases as their fields, and warn of deprecated
+	// uses. Deprecation applies to the aliases of a field if it has any.
+	for _, field := range fields {
+		tag := field.tag // nolint: govet
+		if tag.name == "" || tag.label {
+			continue
+		}
+		if tag.deprecated != "" && len(tag.aliases) == 0 {
+			for _, entry := range mentries[tag.name] {
+				opt.warn(entry.Position(), fmt.Sprintf("%q is deprecated: %s", tag.name, tag.deprecated))
+			}
+		}
+		for _, alias := range tag.aliases {
+			entries, ok := mentries[alias]
+			if !ok {
+				continue
+			}
+			if tag.deprecated != "" {
+				for _, entry := range entries {
+					opt.warn(entry.Position(), fmt.Sprintf("%q is deprecated: %s", alias, tag.deprecated))
+				}
+			}
+			mentries[tag.name] = append(mentries[tag.name], entries...)
+			delete(mentries, alias)
+			if seen[tag.name] == nil {
+				seen[tag.name] = seen[alias]
+			}
+			delete(seen, alias)
+		}
+	}
+	if positions := v.FieldByName("Positions"); positions.IsValid() && positions.Type() == positionsType {
+		out := map[string]Position{}
+		for key, entries := range mentries {
+			if key != "" {
+				out[key] = entries[0].Position()
+			}
+		}
+		positions.Set(reflect.ValueOf(out))
+	}
 	// Apply HCL entries to our fields.
 	for _, field := range fields {
 		tag := field.tag // nolint: govet
@@ -137,7 +185,8 @@ func unmarshalEntries(v reflect.Value, entries []Entry, opt *marshalState) error
 					fv = fv.Elem()
 				}
 				if fv.Kind() == reflect.Struct {
-					if err := unmarshalEntries(fv, nil, opt); err != nil {
+					opt.record(quotePathName(tag.name), Origin{Implicit: true})
+					if err := unmarshalEntries(fv, nil, opt.withPath(quotePathName(tag.name))); err != nil {
 						return fmt.Errorf("failed to hydrate implicit block %q: %w", tag.name, err)
 					}
 					continue
@@ -158,6 +207,7 @@ func unmarshalEntries(v reflect.Value, entries []Entry, opt *marshalState) error
 				if err != nil {
 					return fmt.Errorf("error applying default value to field %q, %v", field.t.Name, err)
 				}
+				opt.record(quotePathName(tag.name), Origin{Default: true})
 			}
 
 			continue
@@ -179,6 +229,7 @@ func unmarshalEntries(v reflect.Value, entries []Entry, opt *marshalState) error
 
 		// Check for unmarshaler interfaces and other special cases.
 		if entry, ok := entry.(*Attribute); ok {
+			opt.record(quotePathName(tag.name), Origin{Pos: entry.Pos})
 			val, isString := entry.Value.(*String)
 			if uv, ok := implements(field.v, jsonUnmarshalerInterface); ok {
 				err := uv.Interface().(json.Unmarshaler).UnmarshalJSON([]byte(val.String()))
@@ -221,7 +272,9 @@ func unmarshalEntries(v reflect.Value, entries []Entry, opt *marshalState) error
 			if entry, ok := entry.(*Attribute); ok {
 				return participle.Errorf(entry.Pos, "expected a block for %q but got an attribute", tag.name)
 			}
-			err := unmarshalBlock(field.v, entry.(*Block), opt)
+			block := entry.(*Block)
+			opt.record(blockPathSegment(block), Origin{Pos: block.Pos})
+			err := unmarshalBlock(field.v, block, opt.withPath(blockPathSegment(block)))
 			if err != nil {
 				return participle.Wrapf(entry.Position(), err, "failed to unmarshal block")
 			}
@@ -243,7 +296,9 @@ func unmarshalEntries(v reflect.Value, entries []Entry, opt *marshalState) error
 						return participle.Errorf(entry.Pos, "expected a block for %q but got an attribute", tag.name)
 					}
 					el := reflect.New(elt).Elem()
-					err := unmarshalBlock(el, entry.(*Block), opt)
+					block := entry.(*Block)
+					opt.record(blockPathSegment(block), Origin{Pos: block.Pos})
+					err := unmarshalBlock(el, block, opt.withPath(blockPathSegment(block)))
 					if err != nil {
 						return participle.Wrapf(entry.Position(), err, "failed to unmarshal block")
 					}
@@ -348,6 +403,9 @@ func unmarshalBlock(v reflect.Value, block *Block, opt *marshalState) error {
 		if uv, ok := implements(field.v, textUnmarshalerInterface); ok {
 			label := labels[0]
 			labels = labels[1:]
+			if err := checkLabel(block, tag, label); err != nil {
+				return err
+			}
 			err := uv.Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(label))
 			if err != nil {
 				return participle.Wrapf(block.Pos, err, "invalid label %q", tag.name)
@@ -355,8 +413,16 @@ func unmarshalBlock(v reflect.Value, block *Block, opt *marshalState) error {
 		} else if field.v.Kind() == reflect.String {
 			label := labels[0]
 			labels = labels[1:]
+			if err := checkLabel(block, tag, label); err != nil {
+				return err
+			}
 			field.v.SetString(label)
 		} else if field.v.Kind() == reflect.Slice && field.v.Type().Elem().Kind() == reflect.String {
+			for _, label := range labels {
+				if err := checkLabel(block, tag, label); err != nil {
+					return err
+				}
+			}
 			field.v.Set(reflect.ValueOf(labels))
 			labels = nil
 		} else {
@@ -369,6 +435,36 @@ func unmarshalBlock(v reflect.Value, block *Block, opt *marshalState) error {
 	return unmarshalEntries(v, block.Body, opt)
 }
 
+// checkLabel checks a label against the enum:"" and pattern:"" tags of its field.
+func checkLabel(block *Block, tag tag, label string) error {
+	if tag.enum != "" {
+		enum := strings.Split(tag.enum, ",")
+		found := false
+		for _, value := range enum {
+			found = found || value == label
+		}
+		if !found {
+			return participle.Errorf(block.Pos, "invalid label %q of block %q: %q does not match anything within enum %s", tag.name, block.Name, label, quoteAll(enum))
+		}
+	}
+	if tag.pattern == "" {
+		return nil
+	}
+	// The pattern was compiled by parseTag.
+	if re, _ := labelPattern(tag.pattern); !re.MatchString(label) {
+		return participle.Errorf(block.Pos, "invalid label %q of block %q: %q does not match pattern %s", tag.name, block.Name, label, tag.pattern)
+	}
+	return nil
+}
+
+func quoteAll(values []string) string {
+	quoted := make([]string, len(values))
+	for i, value := range values {
+		quoted[i] = strconv.Quote(value)
+	}
+	return strings.Join(quoted, ", ")
+}
+
 func unmarshalValue(rv reflect.Value, v Value, opt *marshalState) error {
 	switch rv.Type() {
 	case durationType:
@@ -401,7 +497,7 @@ func unmarshalValue(rv reflect.Value, v Value, opt *marshalState) error {
 		case *String:
 			rv.SetString(v.Str)
 		case *Type:
-			rv.SetString(v.Type)
+			rv.SetString(v.String())
 		case *Heredoc:
 			rv.SetString(v.GetHeredoc())
 		default:
@@ -510,7 +606,7 @@ func unmarshalMapKey(rv reflect.Value, v Value) error {
 		case *String:
 			rv.SetString(v.Str)
 		case *Type:
-			rv.SetString(v.Type)
+			rv.SetString(v.String())
 		case *Heredoc:
 			rv.SetString(v.GetHeredoc())
 		default:
@@ -647,6 +743,10 @@ type tag struct {
 	help         string
 	defaultValue string
 	enum         string
+	merge        string
+	pattern      string
+	aliases      []string
+	deprecated   string
 }
 
 func (t tag) comments(opts *marshalState) []string {
@@ -655,15 +755,73 @@ func (t tag) comments(opts *marshalState) []string {
 		if t.help != "" {
 			lines = append(lines, strings.Split(t.help, "\n")...)
 		}
+		// Schemas record aliases and deprecation on their entries.
+		if !opts.schema {
+			lines = append(lines, aliasComments(t.aliases, t.deprecated)...)
+		}
 		return lines
 	}
 	return nil
 }
 
+// aliasComments describes aliases and deprecation for documentation.
+func aliasComments(aliases []string, deprecated string) []string {
+	switch {
+	case len(aliases) > 0 && deprecated != "":
+		return []string{fmt.Sprintf("Also accepted as %s (deprecated: %s).", strings.Join(aliases, ", "), deprecated)}
+	case len(aliases) > 0:
+		return []string{fmt.Sprintf("Also accepted as %s.", strings.Join(aliases, ", "))}
+	case deprecated != "":
+		return []string{"Deprecated: " + deprecated}
+	}
+	return nil
+}
+
 func parseTag(parent reflect.Type, t reflect.StructField, opt *marshalState) tag {
+	tag := parseTagOptions(parent, t, opt)
+	if pattern := t.Tag.Get("pattern"); pattern != "" {
+		if !tag.label {
+			panic("pattern tag " + pattern + " on non-label field " + fieldID(parent, t))
+		}
+		if _, err := labelPattern(pattern); err != nil {
+			panic("invalid pattern tag " + pattern + " on " + fieldID(parent, t) + ": " + err.Error())
+		}
+		tag.pattern = pattern
+	}
+	return tag
+}
+
+// labelPatterns caches compiled pattern:"" tags by tag value.
+var labelPatterns sync.Map
+
+// labelPattern compiles a pattern:"" tag, which must match the whole label.
+func labelPattern(pattern string) (*regexp.Regexp, error) {
+	if re, ok := labelPatterns.Load(pattern); ok {
+		return re.(*regexp.Regexp), nil
+	}
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, err
+	}
+	labelPatterns.Store(pattern, re)
+	return re, nil
+}
+
+func parseTagOptions(parent reflect.Type, t reflect.StructField, opt *marshalState) tag {
 	help := t.Tag.Get("help")
 	defaultValue := t.Tag.Get("default")
 	enum := t.Tag.Get("enum")
+	merge := t.Tag.Get("merge")
+	if merge != "" && merge != mergeAppend && merge != mergeReplace {
+		panic("invalid merge tag " + merge + " on " + fieldID(parent, t))
+	}
+	var aliases []string
+	for _, alias := range strings.Split(t.Tag.Get("aliases"), ",") {
+		if alias = strings.TrimSpace(alias); alias != "" {
+			aliases = append(aliases, alias)
+		}
+	}
+	deprecated := t.Tag.Get("deprecated")
 	s, ok := t.Tag.Lookup("hcl")
 
 	isBlock := false
@@ -679,7 +837,7 @@ func parseTag(parent reflect.Type, t reflect.StructField, opt *marshalState) tag
 	if !ok {
 		s, ok = t.Tag.Lookup("json")
 		if !ok {
-			return tag{name: t.Name, block: isBlock, optional: true, help: help, defaultValue: defaultValue, enum: enum}
+			return tag{name: t.Name, block: isBlock, optional: true, help: help, defaultValue: defaultValue, enum: enum, merge: merge, aliases: aliases, deprecated: deprecated}
 		}
 	}
 	parts := strings.Split(s, ",")
@@ -692,16 +850,16 @@ func parseTag(parent reflect.Type, t reflect.StructField, opt *marshalState) tag
 		name = t.Name
 	}
 	if len(parts) == 1 {
-		return tag{name: name, block: isBlock, help: help, defaultValue: defaultValue, optional: defaultValue != "", enum: enum}
+		return tag{name: name, block: isBlock, help: help, defaultValue: defaultValue, optional: defaultValue != "", enum: enum, merge: merge, aliases: aliases, deprecated: deprecated}
 	}
 	option := parts[1]
 	switch option {
 	case "optional", "omitempty":
-		return tag{name: name, block: isBlock, optional: true, help: help, defaultValue: defaultValue, enum: enum}
+		return tag{name: name, block: isBlock, optional: true, help: help, defaultValue: defaultValue, enum: enum, merge: merge, aliases: aliases, deprecated: deprecated}
 	case "label":
-		return tag{name: name, label: true, help: help}
+		return tag{name: name, label: true, help: help, enum: enum}
 	case "block":
-		return tag{name: name, block: true, optional: true, help: help}
+		return tag{name: name, block: true, optional: true, help: help, aliases: aliases, deprecated: deprecated}
 	case "embed":
 		return tag{name: name, embed: true, help: help}
 	case "remain":
@@ -1304,3 +1304,138 @@ func TestWithDefaultTransformer(t *testing.T) {
 
 	runTests(t, tests)
 }
+
+func TestUnmarshalPositions(t *testing.T) {
+	type service struct {
+		Name      string              `hcl:"name,label"`
+		Port      int                 `hcl:"port"`
+		Positions map[string]Position `hcl:"-"`
+	}
+	type config struct {
+		Version   int                 `hcl:"version"`
+		Services  []*service          `hcl:"service,block"`
+		Positions map[string]Position `hcl:"-"`
+	}
+	var actual config
+	err := Unmarshal([]byte(`
+version = 1
+
+// Web.
+service web {
+  port = 80
+}
+
+service api {
+
+  port = 8080
+}
+`), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, actual.Positions["version"].Line)
+	assert.Equal(t, 5, actual.Positions["service"].Line)
+	assert.Equal(t, 6, actual.Services[0].Positions["port"].Line)
+	assert.Equal(t, 11, actual.Services[1].Positions["port"].Line)
+	assert.Equal(t, 3, actual.Services[1].Positions["port"].Column)
+}
+
+type labelConstrainedService struct {
+	Kind string   `hcl:"kind,label" help:"Kind of service." enum:"web,db"`
+	Name string   `hcl:"name,label" pattern:"^[a-z]+$"`
+	Tags []string `hcl:"tags,label" pattern:"^[a-z]+$"`
+	Port int      `hcl:"port"`
+}
+
+type labelConstrainedConfig struct {
+	Services []labelConstrainedService `hcl:"service,block"`
+}
+
+func TestUnmarshalLabelConstraints(t *testing.T) {
+	var actual labelConstrainedConfig
+	err := Unmarshal([]byte(`service web api x {
+  port = 80
+}
+`), &actual)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"x"}, actual.Services[0].Tags)
+
+	err = Unmarshal([]byte(`
+service cache api {
+  port = 80
+}
+`), &actual)
+	assert.EqualError(t, err, `2:1: failed to unmarshal block: invalid label "kind" of block "service": "cache" does not match anything within enum "web", "db"`)
+
+	err = Unmarshal([]byte(`
+service db "API" {
+  port = 80
+}
+`), &actual)
+	assert.EqualError(t, err, `2:1: failed to unmarshal block: invalid label "name" of block "service": "API" does not match pattern ^[a-z]+$`)
+
+	err = Unmarshal([]byte(`
+service db api a "B" {
+  port = 80
+}
+`), &actual)
+	assert.EqualError(t, err, `2:1: failed to unmarshal block: invalid label "tags" of block "service": "B" does not match pattern ^[a-z]+$`)
+}
+
+func TestUnmarshalLabelPatternAnchored(t *testing.T) {
+	var actual struct {
+		Services []struct {
+			Name string `hcl:"name,label" pattern:"[a-z]+"`
+		} `hcl:"service,block"`
+	}
+	err := Unmarshal([]byte(`service "ABCx" {}`), &actual)
+	assert.EqualError(t, err, `1:1: failed to unmarshal block: invalid label "name" of block "service": "ABCx" does not match pattern [a-z]+`)
+}
+
+func TestUnmarshalPatternOnNonLabel(t *testing.T) {
+	var actual struct {
+		Name string `hcl:"name" pattern:"[a-z]+"`
+	}
+	assert.Panics(t, func() { _ = Unmarshal([]byte(`name = "a"`), &actual) })
+}
+
+type aliasedConfig struct {
+	Bind    string `hcl:"bind" aliases:"listen_addr, addr," deprecated:"use bind instead"`
+	Timeout int    `hcl:"timeout,optional" deprecated:"timeouts are no longer used"`
+	TLS     *struct {
+		Cert string `hcl:"cert"`
+	} `hcl:"tls,block" aliases:"ssl"`
+}
+
+func TestUnmarshalAliases(t *testing.T) {
+	var warnings []string
+	handler := func(pos Position, msg string) {
+		warnings = append(warnings, fmt.Sprintf("%s: %s", pos, msg))
+	}
+	var actual aliasedConfig
+	err := Unmarshal([]byte(`
+listen_addr = ":80"
+timeout = 10
+ssl {
+  cert = "cert.pem"
+}
+`), &actual, WithWarningHandler(handler))
+	assert.NoError(t, err)
+	assert.Equal(t, ":80", actual.Bind)
+	assert.Equal(t, "cert.pem", actual.TLS.Cert)
+	assert.Equal(t, []string{
+		`2:1: "listen_addr" is deprecated: use bind instead`,
+		`3:1: "timeout" is deprecated: timeouts are no longer used`,
+	}, warnings)
+
+	warnings = nil
+	actual = aliasedConfig{}
+	err = Unmarshal([]byte(`bind = ":80"`), &actual, WithWarningHandler(handler))
+	assert.NoError(t, err)
+	assert.Equal(t, ":80", actual.Bind)
+	assert.Equal(t, nil, warnings)
+
+	err = Unmarshal([]byte(`
+bind = ":80"
+addr = ":81"
+`), &actual)
+	assert.EqualError(t, err, `2:1: duplicate field "bind" at 3:1`)
+}
@@ -61,6 +61,9 @@ func addParentRefs(parent, node Node) {
 
 	case *Type:
 		node.Parent = parent
+		if node.Of != nil {
+			addParentRefs(node, node.Of)
+		}
 
 	case *Heredoc:
 		node.Parent = parent
@@ -81,7 +84,7 @@ func addParentRefs(parent, node Node) {
 		node.Parent = parent
 		addParentRefs(node, node.Value)
 
-	case nil:
+	case *includeDirective, *RecursiveEntry, nil:
 
 	default:
 		panic(fmt.Sprintf("%T", node))
@@ -0,0 +1,262 @@
+package hcl
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/alecthomas/participle/v2"
+)
+
+// ValidateAST checks a document against a schema, such as one produced by
+// Schema or parsed from a published schema file, returning all violations.
+//
+// Attributes must match the types of the schema, be present unless optional
+// or defaulted, and match any enum. Blocks must be declared by the schema with
+// the same number of labels, and may only appear more than once if they are
+// repeated. Attributes and blocks not in the schema are errors, except for
+// their aliases. Blocks that refer to type definitions are validated against
+// the definition.
+func ValidateAST(doc *AST, schema *AST) []error {
+	definitions := schemaDefinitions(schema)
+	return validateEntries(doc.Pos, "", doc.Entries, withoutDefinitions(schema.Entries, definitions), definitions)
+}
+
+func validateEntries(pos Position, path string, entries, schema []Entry, definitions map[string]*Block) (errs []error) {
+	attrs := map[string]*Attribute{}
+	blocks := map[string]*Block{}
+	// A recursive schema body accepts entries it does not declare.
+	recursive := false
+	for _, entry := range schema {
+		switch entry := entry.(type) {
+		case *Attribute:
+			attrs[entry.Key] = entry
+			for _, alias := range entry.Aliases {
+				attrs[alias] = entry
+			}
+		case *Block:
+			blocks[entry.Name] = entry
+			for _, alias := range entry.Aliases {
+				blocks[alias] = entry
+			}
+		case *RecursiveEntry:
+			recursive = true
+		}
+	}
+	seen := map[string]Entry{}
+	for _, entry := range entries {
+		switch entry := entry.(type) {
+		case *Attribute:
+			name := path + entry.Key
+			attr, ok := attrs[entry.Key]
+			// Aliases are tracked under the key of their attribute.
+			key := entry.Key
+			if ok {
+				key = attr.Key
+			}
+			if previous, ok := seen[key]; ok {
+				errs = append(errs, participle.Errorf(entry.Pos, "duplicate attribute %q, previously defined at %s", name, previous.Position()))
+				continue
+			}
+			seen[key] = entry
+			if !ok {
+				if _, ok := blocks[entry.Key]; ok {
+					errs = append(errs, participle.Errorf(entry.Pos, "expected a block for %q but got an attribute", name))
+				} else if !recursive {
+					errs = append(errs, participle.Errorf(entry.Pos, "unknown attribute %q", name))
+				}
+				continue
+			}
+			errs = append(errs, validateAttribute(name, entry, attr)...)
+
+		case *Block:
+			name := path + entry.Name
+			block, ok := blocks[entry.Name]
+			if !ok {
+				if _, ok := attrs[entry.Name]; ok {
+					errs = append(errs, participle.Errorf(entry.Pos, "expected an attribute for %q but got a block", name))
+				} else if !recursive {
+					errs = append(errs, participle.Errorf(entry.Pos, "unknown block %q", name))
+				}
+				continue
+			}
+			if previous, ok := seen[block.Name]; ok && !block.Repeated {
+				errs = append(errs, participle.Errorf(entry.Pos, "block %q is not repeated, but was previously defined at %s", name, previous.Position()))
+				continue
+			}
+			seen[block.Name] = entry
+			if len(entry.Labels) != len(block.Labels) {
+				errs = append(errs, participle.Errorf(entry.Pos, "block %q requires %d labels (%s) but has %d", name, len(block.Labels), strings.Join(block.Labels, ", "), len(entry.Labels)))
+			}
+			body := block.Body
+			if block.Ref != "" {
+				definition, ok := definitions[block.Ref]
+				if !ok {
+					continue
+				}
+				body = definition.Body
+			}
+			errs = append(errs, validateEntries(entry.Pos, name+".", entry.Body, body, definitions)...)
+		}
+	}
+	for _, entry := range schema {
+		if attr, ok := entry.(*Attribute); ok && !attr.Optional && attr.Default == nil && seen[attr.Key] == nil {
+			errs = append(errs, participle.Errorf(pos, "missing required attribute %q", path+attr.Key))
+		}
+	}
+	return errs
+}
+
+func validateAttribute(name string, attr, schema *Attribute) []error {
+	if err := validateValue(attr.Value, schema.Value); err != nil {
+		return []error{participle.Errorf(valuePosition(attr), "invalid value for %q: %s", name, err)}
+	}
+	if len(schema.Enum) == 0 {
+		return nil
+	}
+	enum := make([]string, len(schema.Enum))
+	for i, value := range schema.Enum {
+		if value.String() == attr.Value.String() {
+			return nil
+		}
+		enum[i] = value.String()
+	}
+	return []error{participle.Errorf(valuePosition(attr), "value %s of %q does not match anything within enum %s", attr.Value, name, strings.Join(enum, ", "))}
+}
+
+func valuePosition(attr *Attribute) Position {
+	if attr.Value != nil && attr.Value.Position().Line > 0 {
+		return attr.Value.Position()
+	}
+	return attr.Pos
+}
+
+// validateValue checks that value matches a schema type.
+func validateValue(value, schema Value) error {
+	if value == nil {
+		return fmt.Errorf("missing value")
+	}
+	switch schema := schema.(type) {
+	case *Type:
+		switch schema.Type {
+		case strType:
+			switch value.(type) {
+			case *String, *Heredoc:
+				return nil
+			}
+		case numType:
+			if _, ok := value.(*Number); ok {
+				return nil
+			}
+		case boolType:
+			if _, ok := value.(*Bool); ok {
+				return nil
+			}
+		case durType:
+			if s, ok := value.(*String); ok {
+				if _, err := time.ParseDuration(s.Str); err != nil {
+					return fmt.Errorf("invalid duration %q", s.Str)
+				}
+				return nil
+			}
+		case tsType:
+			if s, ok := value.(*String); ok {
+				if _, err := time.Parse(time.RFC3339, s.Str); err != nil {
+					return fmt.Errorf("invalid time %q, expected RFC3339", s.Str)
+				}
+				return nil
+			}
+		case anyType:
+			return nil
+		case listType:
+			if schema.Of != nil {
+				return validateValue(value, &List{List: []Value{schema.Of}})
+			}
+			return nil
+		case mapType:
+			if schema.Of != nil {
+				return validateValue(value, &Map{Entries: []*MapEntry{{Key: &Type{Type: strType}, Value: schema.Of}}})
+			}
+			return nil
+		default:
+			// Named types are validated as the type they are represented by.
+			if schema.Of != nil {
+				return validateValue(value, schema.Of)
+			}
+			return nil
+		}
+		return fmt.Errorf("expected %s but got %s", schema.Type, valueKind(value))
+
+	case *List:
+		list, ok := value.(*List)
+		if !ok {
+			return fmt.Errorf("expected a list but got %s", valueKind(value))
+		}
+		if len(schema.List) != 1 {
+			return nil
+		}
+		for i, element := range list.List {
+			if err := validateValue(element, schema.List[0]); err != nil {
+				return fmt.Errorf("element %d: %w", i, err)
+			}
+		}
+
+	case *Map:
+		m, ok := value.(*Map)
+		if !ok {
+			return fmt.Errorf("expected a map but got %s", valueKind(value))
+		}
+		if len(schema.Entries) != 1 {
+			return nil
+		}
+		for _, entry := range m.Entries {
+			if err := validateMapKey(entry.Key, schema.Entries[0].Key); err != nil {
+				return fmt.Errorf("key %s: %w", entry.Key, err)
+			}
+			if err := validateValue(entry.Value, schema.Entries[0].Value); err != nil {
+				return fmt.Errorf("%s: %w", entry.Key, err)
+			}
+		}
+
+	case nil:
+
+	default:
+		// A literal in the schema is an example of the expected kind of value.
+		if valueKind(value) != valueKind(schema) {
+			return fmt.Errorf("expected %s but got %s", valueKind(schema), valueKind(value))
+		}
+	}
+	return nil
+}
+
+// validateMapKey additionally allows numeric keys to be quoted.
+func validateMapKey(key, schema Value) error {
+	if t, ok := schema.(*Type); ok && t.Type == numType {
+		if s, ok := key.(*String); ok {
+			if _, err := strconv.ParseFloat(s.Str, 64); err == nil {
+				return nil
+			}
+		}
+	}
+	return validateValue(key, schema)
+}
+
+func valueKind(value Value) string {
+	switch value.(type) {
+	case *String, *Heredoc:
+		return strType
+	case *Number:
+		return numType
+	case *Bool:
+		return boolType
+	case *List:
+		return "list"
+	case *Map:
+		return "map"
+	case *Type:
+		return "type"
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
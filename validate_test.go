@@ -0,0 +1,180 @@
+package hcl
+
+import (
+	"testing"
+
+	"github.com/alecthomas/assert/v2"
+)
+
+func TestValidateAST(t *testing.T) {
+	schema, err := ParseString(expectedSchema)
+	assert.NoError(t, err)
+
+	doc, err := ParseString(`
+str = "hello"
+bool = true
+list = ["a"]
+map = {"a": 1}
+map2 = {"1": "a"}
+block x {
+  attr = "b"
+}
+block_slice a b {
+  attr = "c"
+}
+block_slice c d {
+  attr = "e"
+}
+enum_str = "a"
+`)
+	assert.NoError(t, err)
+	assert.Equal(t, nil, ValidateAST(doc, schema))
+
+	doc, err = ParseString(`
+str = 1
+bool = true
+list = ["a", 2]
+map = {"a": "b"}
+map2 = {"x": "a"}
+block x y {
+  attr = "b"
+  extra = 1
+}
+block z {
+  attr = "b"
+}
+block_slice a {
+  attr = "c"
+}
+enum_str = "d"
+unknown {}
+`)
+	assert.NoError(t, err)
+	errs := []string{}
+	for _, err := range ValidateAST(doc, schema) {
+		errs = append(errs, err.Error())
+	}
+	assert.Equal(t, []string{
+		`2:1: invalid value for "str": expected string but got number`,
+		`4:8: invalid value for "list": element 1: expected string but got number`,
+		`5:7: invalid value for "map": "a": expected number but got string`,
+		`6:8: invalid value for "map2": key "x": expected number but got string`,
+		`7:1: block "block" requires 1 labels (name) but has 2`,
+		`9:3: unknown attribute "block.extra"`,
+		`11:1: block "block" is not repeated, but was previously defined at 7:1`,
+		`14:1: block "block_slice" requires 2 labels (label0, label1) but has 1`,
+		`17:12: value "d" of "enum_str" does not match anything within enum "a", "b", "c"`,
+		`18:1: unknown block "unknown"`,
+	}, errs)
+}
+
+func TestValidateASTRequired(t *testing.T) {
+	schema := MustSchema(&testSchema{})
+	doc, err := ParseString(`
+block x {}
+`)
+	assert.NoError(t, err)
+	errs := []string{}
+	for _, err := range ValidateAST(doc, schema) {
+		errs = append(errs, err.Error())
+	}
+	assert.Equal(t, []string{
+		`2:1: missing required attribute "block.attr"`,
+		`2:1: missing required attribute "str"`,
+		`2:1: missing required attribute "bool"`,
+		`2:1: missing required attribute "list"`,
+		`2:1: missing required attribute "map"`,
+		`2:1: missing required attribute "map2"`,
+		`2:1: missing required attribute "enum_str"`,
+	}, errs)
+}
+
+func TestValidateASTAliases(t *testing.T) {
+	reflected := MustSchema(&aliasedConfig{})
+	data, err := MarshalAST(reflected)
+	assert.NoError(t, err)
+	parsed, err := ParseBytes(data)
+	assert.NoError(t, err)
+
+	doc, err := ParseString(`
+listen_addr = ":80"
+ssl {
+  cert = "cert.pem"
+}
+`)
+	assert.NoError(t, err)
+	var config aliasedConfig
+	assert.NoError(t, UnmarshalAST(doc, &config))
+	for _, schema := range []*AST{reflected, parsed} {
+		assert.Equal(t, nil, ValidateAST(doc, schema))
+	}
+
+	doc, err = ParseString(`
+bind = ":80"
+addr = ":81"
+tls {
+  cert = "cert.pem"
+}
+ssl {
+  cert = "cert.pem"
+}
+`)
+	assert.NoError(t, err)
+	errs := []string{}
+	for _, err := range ValidateAST(doc, parsed) {
+		errs = append(errs, err.Error())
+	}
+	assert.Equal(t, []string{
+		`3:1: duplicate attribute "addr", previously defined at 2:1`,
+		`7:1: block "ssl" is not repeated, but was previously defined at 4:1`,
+	}, errs)
+}
+
+func TestValidateASTRecursiveEntry(t *testing.T) {
+	schema := &AST{Entries: []Entry{&Block{Name: "node", Body: []Entry{
+		&RecursiveEntry{},
+		&Attribute{Key: "name", Value: &Type{Type: strType}},
+	}}}}
+	doc, err := ParseString(`
+node {
+  child {}
+  name = 1
+}
+`)
+	assert.NoError(t, err)
+	errs := ValidateAST(doc, schema)
+	assert.Equal(t, 1, len(errs))
+	assert.EqualError(t, errs[0], `4:3: invalid value for "node.name": expected string but got number`)
+}
+
+func TestValidateASTTypes(t *testing.T) {
+	schema, err := ParseString(`
+timeout = duration
+at = time
+extra = any
+hosts = list(string)
+weights = map(number)
+addr = ip(string)
+`)
+	assert.NoError(t, err)
+	doc, err := ParseString(`
+timeout = "5 minutes"
+at = "2020-01-01"
+extra = {a: [1]}
+hosts = ["a", 2]
+weights = {a: "b"}
+addr = 127
+`)
+	assert.NoError(t, err)
+	errs := []string{}
+	for _, err := range ValidateAST(doc, schema) {
+		errs = append(errs, err.Error())
+	}
+	assert.Equal(t, []string{
+		`2:11: invalid value for "timeout": invalid duration "5 minutes"`,
+		`3:6: invalid value for "at": invalid time "2020-01-01", expected RFC3339`,
+		`5:9: invalid value for "hosts": element 1: expected string but got number`,
+		`6:11: invalid value for "weights": "a": expected number but got string`,
+		`7:1: invalid value for "addr": expected string but got number`,
+	}, errs)
+}